@@ -1,54 +1,193 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"log"
 	"log/slog"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/pseudoerr/mission-service/config"
+	"github.com/pseudoerr/mission-service/internal/auth"
 	"github.com/pseudoerr/mission-service/internal/handler"
 	"github.com/pseudoerr/mission-service/repository"
 	"github.com/pseudoerr/mission-service/service"
 )
 
+// backend is implemented by both service.InMemoryStore and repository.PostgresRepository.
+type backend interface {
+	service.MissionStore
+	service.UserStore
+	service.IdempotencyStore
+	service.SubmissionStore
+	service.AccountStore
+	service.APIKeyStore
+	service.AchievementStore
+	service.LeaderboardStore
+	service.StreakStore
+	service.SeasonStore
+}
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Токен доступа в формате "Bearer <JWT>"
+//
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name Authorization
+// @description API-ключ сервисного клиента в формате "ApiKey <ключ>"
 func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 	config.LoadEnv()
-	db, err := sql.Open("postgres", config.GetDatabaseURL())
-	if err != nil {
-		logger.Warn("failed to connect to db", "postgres", err)
+	var store backend
+	switch config.GetStore() {
+	case "memory":
+		logger.Info("using in-memory store")
+		store = service.NewInMemoryStore()
+	case "postgres":
+		db, err := sql.Open("postgres", config.GetDatabaseURL())
+		if err != nil {
+			logger.Warn("failed to connect to db", "postgres", err)
+		}
+		defer db.Close()
+
+		if err := db.Ping(); err != nil {
+			logger.Warn("failed to ping db", "ping", err)
+		}
+		logger.Info("connected to psql!")
+
+		store = repository.NewPostgresRepository(db)
+	default:
+		log.Fatalf("unknown STORE %q", config.GetStore())
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		logger.Warn("failed to ping db", "ping", err)
+	var limiterStore service.LimiterStore
+	switch config.GetRateLimitStore() {
+	case "memory":
+		limiterStore = service.NewMemoryLimiterStore()
+	case "postgres":
+		pg, ok := store.(*repository.PostgresRepository)
+		if !ok {
+			log.Fatalf("RATE_LIMIT_STORE=postgres requires STORE=postgres")
+		}
+		logger.Info("sharing rate limits through postgres")
+		limiterStore = pg
+	default:
+		log.Fatalf("unknown RATE_LIMIT_STORE %q", config.GetRateLimitStore())
 	}
-	logger.Info("connected to psql!")
 
-	repo := repository.NewPostgresRepository(db)
+	judge := service.NewLocalJudge(config.GetJudgeTimeLimit(), config.GetJudgeMemoryLimitMB())
+	judge.MaxProcesses = config.GetJudgeMaxProcesses()
+	judge.CompileMemoryLimitMB = config.GetJudgeCompileMemoryLimitMB()
+
 	svc := &service.MissionService{
-		Store:  repo,
-		Logger: logger,
+		Store:        store,
+		Users:        store,
+		Idempotency:  store,
+		Submissions:  store,
+		Accounts:     store,
+		APIKeys:      store,
+		Achievements: store,
+		Leaderboards: store,
+		Seasons:      store,
+		Streaks:      store,
+		Judge:        judge,
+		Logger:       logger,
+		// Access token lifetime is configured on the signer below.
+		RefreshTokenTTL: config.GetRefreshTokenTTL(),
 	}
 
-	newHandler := &handler.Handler{Service: svc}
+	if path := config.GetGamificationRulesFile(); path != "" {
+		rules, err := service.LoadRules(path)
+		if err != nil {
+			log.Fatalf("failed to load gamification rules: %v", err)
+		}
+		svc.Rules = rules
+		logger.Info("loaded gamification rules", "file", path, "levels", len(rules.Levels), "badges", len(rules.Badges))
+	}
+
+	authConfig := config.GetAuthConfig()
+	verifier, err := auth.NewVerifier(authConfig)
+	if err != nil {
+		log.Fatalf("failed to configure authentication: %v", err)
+	}
+
+	limiter := handler.NewRateLimiter(handler.RateLimitConfig{
+		Default:        config.GetRateLimit(),
+		Routes:         config.GetRouteRateLimits(),
+		Auth:           config.GetAuthRateLimit(),
+		TrustedProxies: config.GetTrustedProxies(),
+	}, limiterStore)
+	defer limiter.Stop()
+
+	newHandler := &handler.Handler{Service: svc, Auth: verifier, Limiter: limiter}
+	if signer, err := auth.NewSigner(authConfig, config.GetAccessTokenTTL()); err == nil {
+		newHandler.Tokens = signer
+	} else {
+		logger.Warn("local accounts disabled", "reason", err)
+	}
 	router := handler.NewRouter(newHandler)
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool := &service.WorkerPool{
+		Service:      svc,
+		Workers:      config.GetWorkers(),
+		PollInterval: config.GetJobPollInterval(),
+		Lease:        config.GetJobLease(),
+		MaxAttempts:  config.GetJobMaxAttempts(),
+		Logger:       logger,
+	}
+	poolDone := make(chan struct{})
+	go func() {
+		defer close(poolDone)
+		pool.Run(ctx)
+	}()
+	logger.Info("started submission workers", "workers", pool.Workers)
+
+	rollover := &service.SeasonRollover{Service: svc, Interval: config.GetSeasonRolloverInterval(), Logger: logger}
+	rolloverDone := make(chan struct{})
+	go func() {
+		defer close(rolloverDone)
+		rollover.Run(ctx)
+	}()
+
 	go func() {
 		slog.Info("pprof available at :6060/debug/pprof")
 		log.Println(http.ListenAndServe(":6060", nil))
 	}()
 
-	logger.Info("starting http server on port 8080")
-	http.ListenAndServe(":"+port, router)
+	srv := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		logger.Info("starting http server", "port", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("http server failed", "error", err)
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down")
 
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("http server shutdown", "error", err)
+	}
+	<-poolDone
+	<-rolloverDone
 }
@@ -2,9 +2,15 @@ package config
 
 import (
 	"log"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/pseudoerr/mission-service/internal/auth"
+	"github.com/pseudoerr/mission-service/service"
 )
 
 func LoadEnv() {
@@ -20,3 +26,216 @@ func GetDatabaseURL() string {
 	}
 	return url
 }
+
+// GetStore returns the storage backend selected via STORE.
+// Supported values are "postgres" (default) and "memory".
+func GetStore() string {
+	store := os.Getenv("STORE")
+	if store == "" {
+		return "postgres"
+	}
+	return store
+}
+
+// GetAuthConfig returns the keys and expected claims of access tokens:
+// JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE (PEM), JWT_JWKS_FILE,
+// JWT_ISSUER and JWT_AUDIENCE. It exits when JWT_HS256_SECRET is set but
+// too weak to sign tokens.
+func GetAuthConfig() auth.Config {
+	secret := os.Getenv("JWT_HS256_SECRET")
+	if secret != "" {
+		if err := auth.CheckHMACSecret(secret); err != nil {
+			log.Fatalf("invalid JWT_HS256_SECRET: %v", err)
+		}
+	}
+	return auth.Config{
+		HMACSecret:       secret,
+		RSAPublicKeyFile: os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"),
+		JWKSFile:         os.Getenv("JWT_JWKS_FILE"),
+		Issuer:           os.Getenv("JWT_ISSUER"),
+		Audience:         os.Getenv("JWT_AUDIENCE"),
+	}
+}
+
+// GetAccessTokenTTL returns the lifetime of access tokens issued at login (JWT_ACCESS_TTL, default 15m).
+func GetAccessTokenTTL() time.Duration {
+	return getDuration("JWT_ACCESS_TTL", 15*time.Minute)
+}
+
+// GetRefreshTokenTTL returns the lifetime of refresh tokens (REFRESH_TOKEN_TTL, default 720h).
+func GetRefreshTokenTTL() time.Duration {
+	return getDuration("REFRESH_TOKEN_TTL", 720*time.Hour)
+}
+
+// GetRateLimitStore returns where rate limit buckets are kept, selected via
+// RATE_LIMIT_STORE: "memory" (default) per instance, or "postgres" to share
+// limits between instances using the same database.
+func GetRateLimitStore() string {
+	store := os.Getenv("RATE_LIMIT_STORE")
+	if store == "" {
+		return "memory"
+	}
+	return store
+}
+
+// GetRateLimit returns the request limit of every route (RATE_LIMIT, default "10/1m").
+func GetRateLimit() service.RateLimit {
+	return getRateLimit("RATE_LIMIT", "10/1m")
+}
+
+// GetAuthRateLimit returns the per-address limit of requests carrying
+// credentials, checked before them (RATE_LIMIT_AUTH, default "100/1m").
+func GetAuthRateLimit() service.RateLimit {
+	return getRateLimit("RATE_LIMIT_AUTH", "100/1m")
+}
+
+// GetRouteRateLimits returns per-route overrides of the request limit from
+// RATE_LIMIT_ROUTES, e.g. "POST /auth/login=5/1m,POST /missions/{id}/submissions=20/1m".
+func GetRouteRateLimits() map[string]service.RateLimit {
+	v := os.Getenv("RATE_LIMIT_ROUTES")
+	if v == "" {
+		return nil
+	}
+	routes := make(map[string]service.RateLimit)
+	for _, entry := range strings.Split(v, ",") {
+		route, limit, ok := strings.Cut(entry, "=")
+		if !ok {
+			log.Fatalf("invalid RATE_LIMIT_ROUTES entry %q", entry)
+		}
+		l, err := service.ParseRateLimit(limit)
+		if err != nil {
+			log.Fatalf("invalid RATE_LIMIT_ROUTES: %v", err)
+		}
+		routes[strings.TrimSpace(route)] = l
+	}
+	return routes
+}
+
+// GetTrustedProxies returns TRUSTED_PROXIES, the comma-separated addresses
+// or CIDR prefixes whose X-Forwarded-For header identifies the client.
+func GetTrustedProxies() []netip.Prefix {
+	proxies, err := parseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	return proxies
+}
+
+// GetGamificationRulesFile returns the path of the level and badge rules
+// (GAMIFICATION_RULES_FILE, YAML or JSON). The built-in rules are used when
+// it is empty.
+func GetGamificationRulesFile() string {
+	return os.Getenv("GAMIFICATION_RULES_FILE")
+}
+
+// GetJudgeTimeLimit returns the per-test wall clock limit for solutions (JUDGE_TIME_LIMIT, default 2s).
+func GetJudgeTimeLimit() time.Duration {
+	return getDuration("JUDGE_TIME_LIMIT", 2*time.Second)
+}
+
+// GetJudgeMemoryLimitMB returns the address space limit for solutions in MiB (JUDGE_MEMORY_LIMIT_MB, default 256).
+func GetJudgeMemoryLimitMB() int {
+	return getInt("JUDGE_MEMORY_LIMIT_MB", 256)
+}
+
+// GetJudgeCompileMemoryLimitMB returns the address space limit for compilers in MiB (JUDGE_COMPILE_MEMORY_LIMIT_MB, default 2048).
+func GetJudgeCompileMemoryLimitMB() int {
+	return getInt("JUDGE_COMPILE_MEMORY_LIMIT_MB", 2048)
+}
+
+// GetJudgeMaxProcesses returns the process limit for solutions and compilers (JUDGE_MAX_PROCESSES, default 256, 0 disables it).
+func GetJudgeMaxProcesses() int {
+	return getInt("JUDGE_MAX_PROCESSES", 256)
+}
+
+// GetWorkers returns the number of background judging workers (WORKERS, default 2).
+func GetWorkers() int {
+	return getInt("WORKERS", 2)
+}
+
+// GetJobMaxAttempts returns how often a submission is judged before it is dead-lettered (JOB_MAX_ATTEMPTS, default 3).
+func GetJobMaxAttempts() int {
+	return getInt("JOB_MAX_ATTEMPTS", 3)
+}
+
+// GetJobLease returns how long a worker holds a claimed submission (JOB_LEASE, default 2m).
+func GetJobLease() time.Duration {
+	return getDuration("JOB_LEASE", 2*time.Minute)
+}
+
+// GetJobPollInterval returns how often idle workers poll the queue (JOB_POLL_INTERVAL, default 1s).
+func GetJobPollInterval() time.Duration {
+	return getDuration("JOB_POLL_INTERVAL", time.Second)
+}
+
+// GetSeasonRolloverInterval returns how often ended seasons are archived and new ones started (SEASON_ROLLOVER_INTERVAL, default 1m).
+func GetSeasonRolloverInterval() time.Duration {
+	return getDuration("SEASON_ROLLOVER_INTERVAL", time.Minute)
+}
+
+// getDuration reads a positive duration; every duration configured here is
+// a lifetime, limit or interval that zero or less would break.
+func getDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	if d <= 0 {
+		log.Fatalf("invalid %s: must be positive, got %s", key, v)
+	}
+	return d
+}
+
+func getRateLimit(key, def string) service.RateLimit {
+	v := os.Getenv(key)
+	if v == "" {
+		v = def
+	}
+	l, err := service.ParseRateLimit(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return l
+}
+
+func getInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", key, err)
+	}
+	return n
+}
+
+// parseTrustedProxies parses a comma-separated list of addresses and CIDR
+// prefixes.
+func parseTrustedProxies(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if strings.Contains(field, "/") {
+			p, err := netip.ParsePrefix(field)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(field)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// Signer issues HS256 access tokens for local accounts. They carry the
+// issuer and audience of the Config, so a Verifier built from the same
+// Config accepts them.
+type Signer struct {
+	secret   []byte
+	issuer   string
+	audience string
+	ttl      time.Duration
+}
+
+// NewSigner returns a Signer for tokens valid for ttl. It needs a
+// cfg.HMACSecret that passes CheckHMACSecret.
+func NewSigner(cfg Config, ttl time.Duration) (*Signer, error) {
+	if cfg.HMACSecret == "" {
+		return nil, errors.New("auth: issuing tokens requires an HS256 secret")
+	}
+	if err := CheckHMACSecret(cfg.HMACSecret); err != nil {
+		return nil, err
+	}
+	return &Signer{secret: []byte(cfg.HMACSecret), issuer: cfg.Issuer, audience: cfg.Audience, ttl: ttl}, nil
+}
+
+// Sign returns an access token for p and its expiry.
+func (s *Signer) Sign(p models.Principal) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.ttl)
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(p.UserID),
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Username: p.Username,
+		Role:     p.Role,
+	}
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	return token, expiresAt, err
+}
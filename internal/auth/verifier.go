@@ -0,0 +1,171 @@
+// Package auth signs and verifies the bearer tokens that identify callers.
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed, expired,
+// badly signed or carry unusable claims.
+var ErrInvalidToken = errors.New("invalid token")
+
+// MinHMACSecretLen is the length in bytes of the shortest accepted HS256
+// secret, that of the SHA-256 output.
+const MinHMACSecretLen = 32
+
+// ErrWeakSecret is returned for HS256 secrets that are too short or
+// published placeholders: anyone knowing them could sign admin tokens.
+var ErrWeakSecret = fmt.Errorf("auth: HS256 secret must be at least %d random bytes", MinHMACSecretLen)
+
+// knownSecrets are placeholders from examples and documentation.
+var knownSecrets = []string{"change-me", "changeme", "secret", "your-256-bit-secret", "your-secret-key"}
+
+// CheckHMACSecret returns ErrWeakSecret unless secret is fit to sign tokens.
+func CheckHMACSecret(secret string) error {
+	for _, known := range knownSecrets {
+		if strings.EqualFold(secret, known) {
+			return ErrWeakSecret
+		}
+	}
+	if len(secret) < MinHMACSecretLen {
+		return ErrWeakSecret
+	}
+	return nil
+}
+
+// Config selects the keys and expected claims of accepted tokens. At least
+// one of HMACSecret, RSAPublicKeyFile and JWKSFile must be set.
+type Config struct {
+	// HMACSecret verifies HS256 tokens. It must pass CheckHMACSecret.
+	HMACSecret string
+	// RSAPublicKeyFile is a PEM file with the key verifying RS256 tokens.
+	RSAPublicKeyFile string
+	// JWKSFile is a JSON Web Key Set file; its RSA keys verify RS256
+	// tokens, selected by the kid header.
+	JWKSFile string
+	// Issuer and Audience, when set, must match the iss and aud claims.
+	Issuer   string
+	Audience string
+}
+
+// Claims are the JWT claims understood by the service. The subject is the
+// numeric user ID; a missing role means models.RolePlayer.
+type Claims struct {
+	jwt.RegisteredClaims
+	Username string      `json:"preferred_username,omitempty"`
+	Role     models.Role `json:"role,omitempty"`
+}
+
+// Verifier validates HS256 and RS256 bearer tokens.
+type Verifier struct {
+	hmacSecret []byte
+	// rsaKeys are matched by kid. The key of RSAPublicKeyFile has no ID
+	// and verifies tokens whose kid is missing or unknown.
+	rsaKeys []rsaKey
+	parser  *jwt.Parser
+}
+
+type rsaKey struct {
+	id  string
+	key *rsa.PublicKey
+}
+
+// NewVerifier loads the keys named in cfg.
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if cfg.HMACSecret != "" {
+		if err := CheckHMACSecret(cfg.HMACSecret); err != nil {
+			return nil, err
+		}
+	}
+	v := &Verifier{hmacSecret: []byte(cfg.HMACSecret)}
+
+	if cfg.RSAPublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.RSAPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", cfg.RSAPublicKeyFile, err)
+		}
+		v.rsaKeys = append(v.rsaKeys, rsaKey{key: key})
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKeys = append(v.rsaKeys, keys...)
+	}
+	if len(v.hmacSecret) == 0 && len(v.rsaKeys) == 0 {
+		return nil, errors.New("auth: no token verification keys configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify checks the signature and claims of token and returns its principal.
+func (v *Verifier) Verify(token string) (models.Principal, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.key); err != nil {
+		return models.Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil || userID <= 0 {
+		return models.Principal{}, fmt.Errorf("%w: subject must be a user ID", ErrInvalidToken)
+	}
+	role := claims.Role
+	if role == "" {
+		role = models.RolePlayer
+	}
+	if !role.Valid() {
+		return models.Principal{}, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, role)
+	}
+	return models.Principal{UserID: userID, Username: claims.Username, Role: role}, nil
+}
+
+// key picks the verification key for the token's algorithm and kid.
+func (v *Verifier) key(t *jwt.Token) (any, error) {
+	switch t.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if len(v.hmacSecret) == 0 {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return v.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := t.Header["kid"].(string)
+		for _, k := range v.rsaKeys {
+			if k.id == kid {
+				return k.key, nil
+			}
+		}
+		for _, k := range v.rsaKeys {
+			if k.id == "" {
+				return k.key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+}
@@ -0,0 +1,204 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pseudoerr/mission-service/internal/auth"
+	"github.com/pseudoerr/mission-service/models"
+)
+
+const testSecret = "test-secret-of-at-least-32-bytes"
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims auth.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("could not sign token: %v", err)
+	}
+	return s
+}
+
+func claims(sub string, ttl time.Duration) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			Issuer:    "missions",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+		Username: "demo",
+	}
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("could not write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestVerifyHS256(t *testing.T) {
+	v, err := auth.NewVerifier(auth.Config{HMACSecret: testSecret, Issuer: "missions"})
+	if err != nil {
+		t.Fatalf("could not create verifier: %v", err)
+	}
+
+	p, err := v.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims("7", time.Hour)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.UserID != 7 || p.Username != "demo" || p.Role != models.RolePlayer {
+		t.Errorf("unexpected principal %+v", p)
+	}
+
+	invalid := map[string]string{
+		"wrong secret":    sign(t, jwt.SigningMethodHS256, []byte("other"), "", claims("7", time.Hour)),
+		"expired":         sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims("7", -time.Hour)),
+		"non-numeric sub": sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", claims("alice", time.Hour)),
+		"HS512":           sign(t, jwt.SigningMethodHS512, []byte(testSecret), "", claims("7", time.Hour)),
+		"garbage":         "not.a.token",
+	}
+	for name, token := range invalid {
+		if _, err := v.Verify(token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+
+	unknownRole := claims("7", time.Hour)
+	unknownRole.Role = "root"
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", unknownRole)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("unknown role: expected ErrInvalidToken, got %v", err)
+	}
+
+	wrongIssuer := claims("7", time.Hour)
+	wrongIssuer.Issuer = "someone-else"
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS256, []byte(testSecret), "", wrongIssuer)); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("wrong issuer: expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("could not marshal key: %v", err)
+	}
+	pemFile := writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	v, err := auth.NewVerifier(auth.Config{RSAPublicKeyFile: pemFile})
+	if err != nil {
+		t.Fatalf("could not create verifier: %v", err)
+	}
+	if p, err := v.Verify(sign(t, jwt.SigningMethodRS256, key, "", claims("3", time.Hour))); err != nil || p.UserID != 3 {
+		t.Errorf("expected user 3, got %+v (%v)", p, err)
+	}
+	// Without an HMAC secret an HS256 token must not be accepted, whatever its key.
+	if _, err := v.Verify(sign(t, jwt.SigningMethodHS256, der, "", claims("3", time.Hour))); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("HS256 without secret: expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	first, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	second, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+
+	jwk := func(kid string, k *rsa.PublicKey) map[string]string {
+		return map[string]string{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}
+	}
+	set, _ := json.Marshal(map[string]any{"keys": []any{jwk("a", &first.PublicKey), jwk("b", &second.PublicKey)}})
+
+	v, err := auth.NewVerifier(auth.Config{JWKSFile: writeFile(t, "jwks.json", set)})
+	if err != nil {
+		t.Fatalf("could not create verifier: %v", err)
+	}
+	if p, err := v.Verify(sign(t, jwt.SigningMethodRS256, second, "b", claims("5", time.Hour))); err != nil || p.UserID != 5 {
+		t.Errorf("expected user 5, got %+v (%v)", p, err)
+	}
+	if _, err := v.Verify(sign(t, jwt.SigningMethodRS256, second, "a", claims("5", time.Hour))); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("mismatched kid: expected ErrInvalidToken, got %v", err)
+	}
+	if _, err := v.Verify(sign(t, jwt.SigningMethodRS256, first, "c", claims("5", time.Hour))); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("unknown kid: expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestNewVerifierRequiresKeys(t *testing.T) {
+	if _, err := auth.NewVerifier(auth.Config{}); err == nil {
+		t.Error("expected an error without keys")
+	}
+}
+
+func TestWeakSecretsAreRejected(t *testing.T) {
+	for _, secret := range []string{"change-me", "CHANGE-ME", "short-secret", "your-256-bit-secret"} {
+		if _, err := auth.NewVerifier(auth.Config{HMACSecret: secret}); !errors.Is(err, auth.ErrWeakSecret) {
+			t.Errorf("%q: expected ErrWeakSecret from NewVerifier, got %v", secret, err)
+		}
+		if _, err := auth.NewSigner(auth.Config{HMACSecret: secret}, time.Minute); !errors.Is(err, auth.ErrWeakSecret) {
+			t.Errorf("%q: expected ErrWeakSecret from NewSigner, got %v", secret, err)
+		}
+	}
+}
+
+func TestSignerRoundTrip(t *testing.T) {
+	cfg := auth.Config{HMACSecret: testSecret, Issuer: "missions", Audience: "players"}
+	if _, err := auth.NewSigner(auth.Config{}, time.Minute); err == nil {
+		t.Error("expected an error without an HMAC secret")
+	}
+	s, err := auth.NewSigner(cfg, time.Minute)
+	if err != nil {
+		t.Fatalf("could not create signer: %v", err)
+	}
+	v, err := auth.NewVerifier(cfg)
+	if err != nil {
+		t.Fatalf("could not create verifier: %v", err)
+	}
+
+	want := models.Principal{UserID: 7, Username: "alice", Role: models.RoleAuthor}
+	token, expiresAt, err := s.Sign(want)
+	if err != nil {
+		t.Fatalf("could not sign: %v", err)
+	}
+	if time.Until(expiresAt) > time.Minute {
+		t.Errorf("unexpected expiry %v", expiresAt)
+	}
+	got, err := v.Verify(token)
+	if err != nil {
+		t.Fatalf("could not verify signed token: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// CredentialsRequest is the body of POST /auth/register and POST /auth/login.
+type CredentialsRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest is the body of POST /auth/refresh and POST /auth/logout.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse carries a new access token and the refresh token to use
+// for the next refresh.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Register godoc
+// @Summary Регистрация
+// @Description Создает локальную учетную запись игрока
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body CredentialsRequest true "Имя пользователя и пароль"
+// @Success 201 {object} models.User
+// @Failure 400 {object} Problem "Malformed request"
+// @Failure 409 {object} Problem "Username already taken"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to register"
+// @Router /auth/register [post]
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	user, err := h.Service.Register(r.Context(), req.Username, req.Password)
+	if err != nil {
+		writeError(w, r, err, "Failed to register")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// Login godoc
+// @Summary Вход
+// @Description Проверяет пароль и выдает access- и refresh-токены
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body CredentialsRequest true "Имя пользователя и пароль"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} Problem "Malformed request"
+// @Failure 401 {object} Problem "Invalid username or password"
+// @Failure 500 {object} Problem "Failed to log in"
+// @Router /auth/login [post]
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var req CredentialsRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	session, err := h.Service.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		writeError(w, r, err, "Failed to log in")
+		return
+	}
+	h.writeTokens(w, r, session)
+}
+
+// Refresh godoc
+// @Summary Обновление токенов
+// @Description Обменивает refresh-токен на новую пару токенов. Каждый refresh-токен одноразовый;
+// @Description повторное использование отзывает все токены этой сессии.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body RefreshRequest true "Refresh-токен"
+// @Success 200 {object} TokenResponse
+// @Failure 400 {object} Problem "Malformed request"
+// @Failure 401 {object} Problem "Invalid, expired or reused refresh token"
+// @Failure 500 {object} Problem "Failed to refresh"
+// @Router /auth/refresh [post]
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	session, err := h.Service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeError(w, r, err, "Failed to refresh")
+		return
+	}
+	h.writeTokens(w, r, session)
+}
+
+// Logout godoc
+// @Summary Выход
+// @Description Отзывает refresh-токен и все токены, выданные вместо него
+// @Tags auth
+// @Accept json
+// @Param request body RefreshRequest true "Refresh-токен"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} Problem "Malformed request"
+// @Failure 500 {object} Problem "Failed to log out"
+// @Router /auth/logout [post]
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	if err := h.Service.Logout(r.Context(), req.RefreshToken); err != nil {
+		writeError(w, r, err, "Failed to log out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTokens signs an access token for the session's user and writes it
+// together with the refresh token.
+func (h *Handler) writeTokens(w http.ResponseWriter, r *http.Request, session service.Session) {
+	u := session.User
+	access, expiresAt, err := h.Tokens.Sign(models.Principal{UserID: u.ID, Username: u.Username, Role: u.Role})
+	if err != nil {
+		writeError(w, r, err, "Failed to issue token")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, TokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(time.Until(expiresAt).Round(time.Second).Seconds()),
+		RefreshToken: session.RefreshToken,
+	})
+}
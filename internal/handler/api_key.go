@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// CreateAPIKeyRequest is the body of POST /api-keys.
+type CreateAPIKeyRequest struct {
+	Name   string         `json:"name"`
+	Scopes []models.Scope `json:"scopes" enums:"missions:read,missions:write,submissions:write"`
+}
+
+// APIKeyResponse is a newly created key. Key is shown only once.
+type APIKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey godoc
+// @Summary Создать API-ключ
+// @Description Выпускает ключ для сервисных клиентов, действующий от имени пользователя в пределах scopes.
+// @Description Секрет возвращается один раз и не хранится на сервере.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body CreateAPIKeyRequest true "Название и scopes ключа"
+// @Success 201 {object} APIKeyResponse
+// @Failure 400 {object} Problem "Malformed request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "API keys cannot manage keys"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to create API key"
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	key, secret, err := h.Service.CreateAPIKey(r.Context(), req.Name, req.Scopes)
+	if err != nil {
+		writeError(w, r, err, "Failed to create API key")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusCreated, APIKeyResponse{APIKey: key, Key: secret})
+}
+
+// ListAPIKeys godoc
+// @Summary Список API-ключей
+// @Description Возвращает ключи текущего пользователя; администраторы видят все ключи
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "API keys cannot manage keys"
+// @Failure 500 {object} Problem "Failed to list API keys"
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.Service.ListAPIKeys(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Failed to list API keys")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Отозвать API-ключ
+// @Description Отзывает ключ; запросы с ним получают 401
+// @Tags api-keys
+// @Param id path int true "ID ключа"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "API keys cannot manage keys"
+// @Failure 404 {object} Problem "Not found or not the caller's key"
+// @Failure 500 {object} Problem "Failed to revoke API key"
+// @Security BearerAuth
+// @Router /api-keys/{id} [delete]
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.RevokeAPIKey(r.Context(), id); err != nil {
+		writeError(w, r, err, "Failed to revoke API key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
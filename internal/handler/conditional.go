@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// etag formats a mission version as a strong entity tag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// requireIfMatch reads the version the client expects from If-Match.
+// "*" matches any version and is returned as 0. It answers 428 when the
+// header is missing and 412 when it cannot match any version; ok is false
+// in both cases.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		writeProblem(w, r, http.StatusPreconditionRequired, CodePreconditionRequired, "If-Match header is required")
+		return 0, false
+	}
+	if header == "*" {
+		return 0, true
+	}
+
+	// If-Match uses the strong comparison, so weak tags never match.
+	unquoted, found := strings.CutPrefix(header, `"`)
+	unquoted, closed := strings.CutSuffix(unquoted, `"`)
+	version, err := strconv.Atoi(unquoted)
+	if !found || !closed || err != nil || version <= 0 {
+		writeProblem(w, r, http.StatusPreconditionFailed, CodePreconditionFailed, "If-Match does not match the current version")
+		return 0, false
+	}
+	return version, true
+}
+
+// notModified reports whether If-None-Match matches tag, using the weak
+// comparison as required for GET.
+func notModified(r *http.Request, tag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
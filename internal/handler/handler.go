@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
+	"github.com/pseudoerr/mission-service/internal/auth"
 	"github.com/pseudoerr/mission-service/models"
 	"github.com/pseudoerr/mission-service/service"
 	"log/slog"
@@ -15,61 +17,119 @@ import (
 
 type Handler struct {
 	Service *service.MissionService
+	// Auth verifies bearer tokens. Without it every request is anonymous and
+	// endpoints requiring authentication answer 401.
+	Auth *auth.Verifier
+	// Tokens signs access tokens for local accounts. The /auth endpoints
+	// are only routed when it is set.
+	Tokens *auth.Signer
+	// Limiter rate-limits routed requests; requests are not limited when
+	// it is nil.
+	Limiter *RateLimiter
 }
 
 // GetMissions godoc
-// @Summary Получить все задания
-// @Description Возвращает список всех заданий
+// @Summary Получить задания
+// @Description Возвращает страницу заданий с фильтрацией и сортировкой.
+// @Description Следующая страница запрашивается с параметром cursor, равным next_cursor из ответа.
 // @Tags missions
 // @Produce json
-// @Success 200 {array} models.Mission
-// @Failure 504 {string} string "Request timeout"
-// @Failure 500 {string} string "Failed to list missions"
+// @Param difficulty query string false "Сложность" Enums(easy, medium, hard)
+// @Param tag query string false "Тег"
+// @Param category query string false "Категория"
+// @Param min_points query int false "Минимум очков"
+// @Param max_points query int false "Максимум очков"
+// @Param sort query string false "Сортировка" Enums(points, -points, created_at, -created_at)
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.MissionPage
+// @Failure 400 {object} Problem "Invalid query"
+// @Failure 422 {object} Problem "Invalid sort or cursor"
+// @Failure 500 {object} Problem "Failed to list missions"
+// @Failure 504 {object} Problem "Request timeout"
 // @Router /missions [get]
 func (h *Handler) GetMissions(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid query: "+err.Error())
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 	defer cancel()
-	missions, err := h.Service.Store.ListMissions(ctx)
+	page, err := h.Service.ListMissions(ctx, opts)
 	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			slog.Warn("Mission fetch timed out")
-			http.Error(w, "Request timeout", http.StatusGatewayTimeout)
+		writeError(w, r, err, "Failed to list missions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// SearchMissions godoc
+// @Summary Поиск заданий
+// @Description Полнотекстовый поиск по названию и описанию; результаты отсортированы по релевантности
+// @Tags missions
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param limit query int false "Количество результатов (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.MissionPage
+// @Failure 400 {object} Problem "Invalid query"
+// @Failure 422 {object} Problem "Empty search query"
+// @Failure 500 {object} Problem "Failed to search missions"
+// @Router /missions/search [get]
+func (h *Handler) SearchMissions(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid query: limit must be a positive integer")
 			return
 		}
-		http.Error(w, "Failed to list missions", http.StatusInternalServerError)
-		return
+		limit = n
 	}
 
-	if missions == nil {
-		missions = []models.Mission{}
+	missions, err := h.Service.SearchMissions(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeError(w, r, err, "Failed to search missions")
+		return
 	}
 
-	writeJSON(w, http.StatusOK, missions)
+	writeJSON(w, http.StatusOK, models.MissionPage{Items: missions})
 }
 
 // GetMissionByID godoc
 // @Summary Получить задание по ID
-// @Description Возвращает одно задание по его идентификатору
+// @Description Возвращает одно задание по его идентификатору. Версия задания передается в заголовке ETag.
 // @Tags missions
 // @Produce json
 // @Param id path int true "ID задания"
+// @Param If-None-Match header string false "ETag, сохраненный клиентом"
 // @Success 200 {object} models.Mission
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Not found"
+// @Success 304 {string} string "Not Modified"
+// @Header 200,304 {string} ETag "Версия задания"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 500 {object} Problem "Failed to get mission"
 // @Router /missions/{id} [get]
 func (h *Handler) GetMissionByID(w http.ResponseWriter, r *http.Request) {
-	id, err := parseID(r)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+	id, ok := pathID(w, r)
+	if !ok {
 		return
 	}
 
 	mission, err := h.Service.Store.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Not found", http.StatusNotFound)
+		writeError(w, r, err, "Failed to get mission")
 		return
 	}
 
+	tag := etag(mission.Version)
+	w.Header().Set("ETag", tag)
+	if notModified(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	writeJSON(w, http.StatusOK, mission)
 }
 
@@ -81,99 +141,445 @@ func (h *Handler) GetMissionByID(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param mission body models.Mission true "Новое задание"
 // @Success 201 {object} models.Mission
-// @Failure 400 {string} string "Invalid request"
-// @Failure 500 {string} string "Failed to create mission"
+// @Failure 400 {object} Problem "Invalid request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Author or admin role required"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to create mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /missions [post]
 func (h *Handler) CreateMission(w http.ResponseWriter, r *http.Request) {
 	var m models.Mission
-	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &m); err != nil {
+		writeError(w, r, err, "Invalid request")
 		return
 	}
 
-	created, err := h.Service.Store.AddMission(r.Context(), m)
+	created, err := h.Service.CreateMission(r.Context(), m)
 	if err != nil {
-		http.Error(w, "Failed to create mission", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to create mission")
 		return
 	}
 
+	w.Header().Set("ETag", etag(created.Version))
 	writeJSON(w, http.StatusCreated, created)
 }
 
 // UpdateMission godoc
 // @Summary Обновить задание
-// @Description Обновляет существующее задание по ID
+// @Description Обновляет существующее задание по ID. Требует заголовок If-Match с ETag текущей версии.
 // @Tags missions
 // @Accept json
 // @Produce json
 // @Param id path int true "ID задания"
+// @Param If-Match header string true "ETag текущей версии"
 // @Param mission body models.Mission true "Обновленные данные"
 // @Success 200 {object} models.Mission
-// @Failure 400 {string} string "Invalid ID or request"
-// @Failure 500 {string} string "Failed to update mission"
+// @Header 200 {string} ETag "Новая версия задания"
+// @Failure 400 {object} Problem "Invalid ID or request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an admin or the mission's author"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 412 {object} Problem "Version mismatch"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 428 {object} Problem "If-Match header is required"
+// @Failure 500 {object} Problem "Failed to update mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /missions/{id} [put]
 func (h *Handler) UpdateMission(w http.ResponseWriter, r *http.Request) {
-	id, err := parseID(r)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
 
 	var m models.Mission
-	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+	if err := decodeJSON(w, r, &m); err != nil {
+		writeError(w, r, err, "Invalid request")
 		return
 	}
 
 	m.ID = id
+	m.Version = version
+
+	updated, err := h.Service.UpdateMission(r.Context(), m)
+	if err != nil {
+		writeError(w, r, err, "Failed to update mission")
+		return
+	}
+
+	w.Header().Set("ETag", etag(updated.Version))
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// PatchMission godoc
+// @Summary Частично обновить задание
+// @Description Применяет JSON Merge Patch (RFC 7396): переданные поля заменяются, null удаляет значение, остальные поля не меняются. Требует заголовок If-Match с ETag текущей версии.
+// @Tags missions
+// @Accept application/merge-patch+json
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задания"
+// @Param If-Match header string true "ETag текущей версии"
+// @Param patch body models.Mission true "Изменяемые поля"
+// @Success 200 {object} models.Mission
+// @Header 200 {string} ETag "Новая версия задания"
+// @Failure 400 {object} Problem "Invalid ID or request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an admin or the mission's author"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 412 {object} Problem "Version mismatch"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 415 {object} Problem "Unsupported media type"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 428 {object} Problem "If-Match header is required"
+// @Failure 500 {object} Problem "Failed to update mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/{id} [patch]
+func (h *Handler) PatchMission(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	if !isMergePatch(r) {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Content-Type must be application/merge-patch+json")
+		return
+	}
+
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	patch, err := decodeMergePatch(w, r, &models.Mission{})
+	if err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
 
-	updated, err := h.Service.Store.UpdateMission(r.Context(), m)
+	updated, err := h.Service.PatchMission(r.Context(), id, version, patch)
 	if err != nil {
-		http.Error(w, "Failed to update mission", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to update mission")
 		return
 	}
 
+	w.Header().Set("ETag", etag(updated.Version))
 	writeJSON(w, http.StatusOK, updated)
 }
 
 // DeleteMission godoc
 // @Summary Удалить задание
-// @Description Удаляет задание по ID
+// @Description Перемещает задание в корзину. Требует заголовок If-Match с ETag текущей версии.
 // @Tags missions
 // @Param id path int true "ID задания"
+// @Param If-Match header string true "ETag текущей версии"
 // @Success 204 {string} string "No Content"
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 500 {string} string "Failed to delete mission"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an admin or the mission's author"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 412 {object} Problem "Version mismatch"
+// @Failure 428 {object} Problem "If-Match header is required"
+// @Failure 500 {object} Problem "Failed to delete mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /missions/{id} [delete]
 func (h *Handler) DeleteMission(w http.ResponseWriter, r *http.Request) {
-	id, err := parseID(r)
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Service.DeleteMission(r.Context(), id, version); err != nil {
+		writeError(w, r, err, "Failed to delete mission")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTrash godoc
+// @Summary Корзина заданий
+// @Description Возвращает страницу удаленных заданий; параметры те же, что у списка заданий.
+// @Tags missions
+// @Produce json
+// @Param difficulty query string false "Сложность" Enums(easy, medium, hard)
+// @Param tag query string false "Тег"
+// @Param category query string false "Категория"
+// @Param min_points query int false "Минимум очков"
+// @Param max_points query int false "Максимум очков"
+// @Param sort query string false "Сортировка" Enums(points, -points, created_at, -created_at)
+// @Param cursor query string false "Курсор следующей страницы"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.MissionPage
+// @Failure 400 {object} Problem "Invalid query"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Author or admin role required"
+// @Failure 422 {object} Problem "Invalid sort or cursor"
+// @Failure 500 {object} Problem "Failed to list missions"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/trash [get]
+func (h *Handler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid query: "+err.Error())
+		return
+	}
+
+	page, err := h.Service.ListTrash(r.Context(), opts)
+	if err != nil {
+		writeError(w, r, err, "Failed to list missions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// RestoreMission godoc
+// @Summary Восстановить задание
+// @Description Возвращает удаленное задание из корзины
+// @Tags missions
+// @Produce json
+// @Param id path int true "ID задания"
+// @Success 200 {object} models.Mission
+// @Header 200 {string} ETag "Новая версия задания"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an admin or the mission's author"
+// @Failure 404 {object} Problem "Not in trash"
+// @Failure 500 {object} Problem "Failed to restore mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/{id}/restore [post]
+func (h *Handler) RestoreMission(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	restored, err := h.Service.RestoreMission(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Failed to restore mission")
+		return
+	}
+
+	w.Header().Set("ETag", etag(restored.Version))
+	writeJSON(w, http.StatusOK, restored)
+}
+
+// PurgeMission godoc
+// @Summary Окончательно удалить задание
+// @Description Удаляет задание без возможности восстановления вместе с прохождениями, тестами и решениями. Доступно администраторам.
+// @Tags admin
+// @Param id path int true "ID задания"
+// @Success 204 {string} string "No Content"
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Admin role required"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 500 {object} Problem "Failed to purge mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /admin/missions/{id} [delete]
+func (h *Handler) PurgeMission(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
 		return
 	}
-	if err := h.Service.Store.DeleteMission(r.Context(), id); err != nil {
-		http.Error(w, "Failed to delete mission", http.StatusInternalServerError)
+
+	if err := h.Service.Store.PurgeMission(r.Context(), id); err != nil {
+		writeError(w, r, err, "Failed to purge mission")
 		return
 	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// GetProfile godoc
-// @Summary Получить профиль
-// @Description Возвращает профиль текущего пользователя/сервиса
+// CompleteMissionRequest is the optional body of POST /missions/{id}/complete.
+type CompleteMissionRequest struct {
+	// UserID defaults to the authenticated user and must match it when set.
+	UserID int `json:"user_id,omitempty"`
+}
+
+// CompleteMission godoc
+// @Summary Отметить задание выполненным
+// @Description Фиксирует выполнение задания текущим пользователем и возвращает обновленный профиль.
+// @Description Доступно авторам и администраторам, игроки получают очки за принятые решения.
+// @Description Повторные запросы с тем же заголовком Idempotency-Key получают сохраненный ответ.
+// @Tags missions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задания"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Param request body CompleteMissionRequest false "Пользователь"
+// @Success 200 {object} models.Profile
+// @Failure 400 {object} Problem "Invalid ID or request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an author or admin, or user_id of another user"
+// @Failure 404 {object} Problem "Mission or user not found"
+// @Failure 409 {object} Problem "Mission already completed"
+// @Failure 422 {object} Problem "Invalid body or Idempotency-Key reused for a different request"
+// @Failure 500 {object} Problem "Failed to complete mission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/{id}/complete [post]
+func (h *Handler) CompleteMission(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req CompleteMissionRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, r, err, "Invalid request")
+			return
+		}
+	}
+	if req.UserID, ok = actingUser(w, r, req.UserID); !ok {
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" || h.Service.Idempotency == nil {
+		profile, err := h.Service.CompleteMission(r.Context(), req.UserID, id)
+		if err != nil {
+			writeError(w, r, err, "Failed to complete mission")
+			return
+		}
+		writeJSON(w, http.StatusOK, profile)
+		return
+	}
+
+	if h.replayIdempotent(w, r, req.UserID, key) {
+		return
+	}
+	profile, err := h.Service.CompleteMissionWithKey(r.Context(), req.UserID, id, key, r.URL.Path)
+	if errors.Is(err, service.ErrIdempotencyKeyTaken) && h.replayIdempotent(w, r, req.UserID, key) {
+		// A concurrent request with the same key won the race.
+		return
+	}
+	if err != nil {
+		writeError(w, r, err, "Failed to complete mission")
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(profile); err != nil {
+		writeError(w, r, err, "Failed to complete mission")
+		return
+	}
+	err = h.Service.Idempotency.SaveIdempotencyRecord(r.Context(), models.IdempotencyRecord{
+		Key:        key,
+		UserID:     req.UserID,
+		Path:       r.URL.Path,
+		StatusCode: http.StatusOK,
+		Body:       body.Bytes(),
+	})
+	if err != nil {
+		// Retries still get the current profile, see replayIdempotent.
+		slog.Warn("failed to save idempotency record", "key", key, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body.Bytes())
+}
+
+// replayIdempotent answers a request whose Idempotency-Key was used before
+// and reports whether it did. Keys are reserved together with the
+// completion, so a key without a stored response belongs to a completion
+// that happened; it is answered with the current profile.
+func (h *Handler) replayIdempotent(w http.ResponseWriter, r *http.Request, userID int, key string) bool {
+	rec, err := h.Service.Idempotency.GetIdempotencyRecord(r.Context(), userID, key)
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return false
+	case err != nil:
+		writeError(w, r, err, "Failed to complete mission")
+		return true
+	case rec.Path != r.URL.Path:
+		writeProblem(w, r, http.StatusUnprocessableEntity, CodeValidation, "Idempotency-Key reused for a different request")
+		return true
+	}
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	if rec.StatusCode == 0 {
+		profile, err := h.Service.GetProfile(r.Context(), userID)
+		if err != nil {
+			writeError(w, r, err, "Failed to complete mission")
+			return true
+		}
+		writeJSON(w, http.StatusOK, profile)
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(rec.StatusCode)
+	_, _ = w.Write(rec.Body)
+	return true
+}
+
+// GetUserProfile godoc
+// @Summary Получить профиль пользователя
+// @Description Возвращает профиль пользователя, рассчитанный по выполненным им заданиям
 // @Tags profile
 // @Produce json
+// @Param id path int true "ID пользователя"
 // @Success 200 {object} models.Profile
-// @Failure 500 {string} string "Failed to get profile"
-// @Router /profile [get]
-func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	profile, err := h.Service.GetProfile(r.Context())
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Failed to get profile"
+// @Router /users/{id}/profile [get]
+func (h *Handler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.Service.GetProfile(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Failed to get profile", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to get profile")
 		return
 	}
 	writeJSON(w, http.StatusOK, profile)
 }
+
+// GetGamificationRules godoc
+// @Summary Правила уровней и значков
+// @Description Возвращает действующие правила расчета уровней и значков в профиле
+// @Tags profile
+// @Produce json
+// @Success 200 {object} models.GamificationRules
+// @Router /gamification/rules [get]
+func (h *Handler) GetGamificationRules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.Service.GamificationRules())
+}
+
+// actingUser returns the user a request acts for: the authenticated user.
+// A user ID from the request body may repeat it but not name someone
+// else; that is answered with 403.
+func actingUser(w http.ResponseWriter, r *http.Request, requested int) (int, bool) {
+	userID := service.CallerID(r.Context())
+	if requested != 0 && requested != userID {
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, "Cannot act on behalf of another user")
+		return 0, false
+	}
+	return userID, true
+}
+
 func writeJSON(w http.ResponseWriter, code int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -184,3 +590,55 @@ func parseID(r *http.Request) (int, error) {
 	vars := mux.Vars(r)
 	return strconv.Atoi(vars["id"])
 }
+
+// pathID parses the {id} route variable and answers 400 when it is invalid.
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := parseID(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid ID")
+		return 0, false
+	}
+	return id, true
+}
+
+func parseListOptions(r *http.Request) (service.ListOptions, error) {
+	q := r.URL.Query()
+	opts := service.ListOptions{
+		Difficulty: models.Difficulty(q.Get("difficulty")),
+		Tag:        q.Get("tag"),
+		Category:   q.Get("category"),
+		Sort:       q.Get("sort"),
+		Cursor:     q.Get("cursor"),
+	}
+
+	intParam := func(name string) (*int, error) {
+		v := q.Get(name)
+		if v == "" {
+			return nil, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New(name + " must be an integer")
+		}
+		return &n, nil
+	}
+
+	var err error
+	if opts.MinPoints, err = intParam("min_points"); err != nil {
+		return opts, err
+	}
+	if opts.MaxPoints, err = intParam("max_points"); err != nil {
+		return opts, err
+	}
+	limit, err := intParam("limit")
+	if err != nil {
+		return opts, err
+	}
+	if limit != nil {
+		if *limit <= 0 {
+			return opts, errors.New("limit must be positive")
+		}
+		opts.Limit = *limit
+	}
+	return opts, nil
+}
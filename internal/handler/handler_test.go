@@ -3,22 +3,69 @@ package handler_test
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pseudoerr/mission-service/internal/auth"
 	"github.com/pseudoerr/mission-service/internal/handler"
 	"github.com/pseudoerr/mission-service/models"
 	"github.com/pseudoerr/mission-service/service"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
+const testSecret = "test-secret-of-at-least-32-bytes"
+
+// newTestHandler returns a handler accepting HS256 tokens signed with testSecret.
+func newTestHandler(t *testing.T, svc *service.MissionService) *handler.Handler {
+	t.Helper()
+	verifier, err := auth.NewVerifier(auth.Config{HMACSecret: testSecret})
+	if err != nil {
+		t.Fatalf("could not create verifier: %v", err)
+	}
+	return &handler.Handler{Service: svc, Auth: verifier}
+}
+
+// mintToken signs an access token for userID with testSecret.
+func mintToken(t *testing.T, userID int, role models.Role) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Role: role,
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("could not sign token: %v", err)
+	}
+	return token
+}
+
+// asUser authenticates every request sent to h as userID with role.
+func asUser(t *testing.T, h http.Handler, userID int, role models.Role) http.Handler {
+	token := mintToken(t, userID, role)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+		h.ServeHTTP(w, r)
+	})
+}
+
 type fakeStore struct {
 	missions []models.Mission
 	nextID   int
 }
 
-func (f *fakeStore) ListMissions(ctx context.Context) ([]models.Mission, error) {
-	return f.missions, nil
+func (f *fakeStore) ListMissions(ctx context.Context, opts service.ListOptions) (models.MissionPage, error) {
+	return models.MissionPage{Items: f.missions}, nil
+}
+
+func (f *fakeStore) SearchMissions(ctx context.Context, query string, limit int) ([]models.Mission, error) {
+	return nil, nil
 }
 
 func (f *fakeStore) AddMission(ctx context.Context, m models.Mission) (models.Mission, error) {
@@ -34,15 +81,39 @@ func (f *fakeStore) GetByID(ctx context.Context, id int) (models.Mission, error)
 			return m, nil
 		}
 	}
-	return models.Mission{}, fmt.Errorf("not found")
+	return models.Mission{}, service.ErrNotFound
 }
 
 func (f *fakeStore) UpdateMission(ctx context.Context, m models.Mission) (models.Mission, error) {
-	return m, nil
+	for i := range f.missions {
+		if f.missions[i].ID == m.ID {
+			f.missions[i] = m
+			return m, nil
+		}
+	}
+	return models.Mission{}, service.ErrNotFound
 }
 
-func (f *fakeStore) DeleteMission(ctx context.Context, id int) error {
-	return nil
+func (f *fakeStore) DeleteMission(ctx context.Context, id, version int) error {
+	for i := range f.missions {
+		if f.missions[i].ID == id {
+			f.missions = append(f.missions[:i], f.missions[i+1:]...)
+			return nil
+		}
+	}
+	return service.ErrNotFound
+}
+
+func (f *fakeStore) GetDeleted(ctx context.Context, id int) (models.Mission, error) {
+	return models.Mission{}, service.ErrNotFound
+}
+
+func (f *fakeStore) RestoreMission(ctx context.Context, id int) (models.Mission, error) {
+	return models.Mission{}, service.ErrNotFound
+}
+
+func (f *fakeStore) PurgeMission(ctx context.Context, id int) error {
+	return f.DeleteMission(ctx, id, 0)
 }
 
 func TestGetMissions(t *testing.T) {
@@ -66,12 +137,780 @@ func TestGetMissions(t *testing.T) {
 		t.Fatalf("expected 200, got %d", rec.Code)
 	}
 
-	var data []models.Mission
+	var data models.MissionPage
 	if err := json.NewDecoder(rec.Body).Decode(&data); err != nil {
 		t.Fatalf("bad json: %v", err)
 	}
 
-	if len(data) != 1 || data[0].Title != "Test" {
+	if len(data.Items) != 1 || data.Items[0].Title != "Test" {
 		t.Fatalf("unexpected data: %+v", data)
 	}
 }
+
+func TestCompleteMissionIdempotency(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Idempotency: store}
+	router := asUser(t, handler.NewRouter(newTestHandler(t, svc)), 1, models.RoleAuthor)
+
+	complete := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/missions/1/complete", strings.NewReader(`{"user_id": 1}`))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := complete("retry-1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", first.Code)
+	}
+
+	replay := complete("retry-1")
+	if replay.Code != http.StatusOK {
+		t.Fatalf("expected replayed 200, got %d", replay.Code)
+	}
+	if replay.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %q, got %q", first.Body.String(), replay.Body.String())
+	}
+
+	if dup := complete(""); dup.Code != http.StatusConflict {
+		t.Errorf("expected 409 for duplicate completion, got %d", dup.Code)
+	}
+
+	// The response of a completion was never stored, e.g. after a crash:
+	// retries get the current profile instead of 409.
+	rec := models.IdempotencyRecord{Key: "retry-2", UserID: 1, Path: "/missions/2/complete"}
+	if err := store.AddCompletionWithKey(context.Background(), rec, 2); err != nil {
+		t.Fatalf("could not reserve key: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/missions/2/complete", nil)
+	req.Header.Set("Idempotency-Key", "retry-2")
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+	var profile models.Profile
+	if err := json.NewDecoder(res.Body).Decode(&profile); err != nil || res.Code != http.StatusOK || profile.TotalPoints != 300 {
+		t.Errorf("expected the current profile, got %d %+v (%v)", res.Code, profile, err)
+	}
+	if res.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected the response to be marked as replayed")
+	}
+}
+
+func TestCreateMissionValidation(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := asUser(t, handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store})), 1, models.RoleAdmin)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		field  string
+	}{
+		{"invalid fields", `{"title": "", "points": -1}`, http.StatusUnprocessableEntity, "title"},
+		{"unknown field", `{"title": "Two Sum", "points": 10, "reward": 5}`, http.StatusUnprocessableEntity, "reward"},
+		{"wrong type", `{"title": "Two Sum", "points": "ten"}`, http.StatusUnprocessableEntity, "points"},
+		{"malformed", `{"title": `, http.StatusBadRequest, ""},
+		{"too large", `{"title": "` + strings.Repeat("a", 2<<20) + `"}`, http.StatusRequestEntityTooLarge, ""},
+		{"valid", `{"title": "Two Sum", "points": 10}`, http.StatusCreated, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/missions", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.field == "" {
+				return
+			}
+
+			var resp handler.Problem
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("bad json: %v", err)
+			}
+			found := false
+			for _, f := range resp.Errors {
+				if f.Field == tt.field {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected field %s in %+v", tt.field, resp.Errors)
+			}
+		})
+	}
+}
+
+func TestErrorsUseProblemDetails(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := handler.NewRouter(&handler.Handler{Service: &service.MissionService{Store: store, Users: store}})
+
+	tests := []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{"/missions/999", http.StatusNotFound, handler.CodeNotFound},
+		{"/users/999/profile", http.StatusNotFound, handler.CodeNotFound},
+		{"/missions?limit=abc", http.StatusBadRequest, handler.CodeBadRequest},
+		{"/missions?sort=title", http.StatusUnprocessableEntity, handler.CodeValidation},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("X-Request-ID", "req-42")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.path, tt.status, rec.Code)
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s: expected problem+json, got %s", tt.path, ct)
+		}
+
+		var p handler.Problem
+		if err := json.NewDecoder(rec.Body).Decode(&p); err != nil {
+			t.Fatalf("%s: bad json: %v", tt.path, err)
+		}
+		if p.Code != tt.code || p.Status != tt.status || p.RequestID != "req-42" {
+			t.Errorf("%s: unexpected problem %+v", tt.path, p)
+		}
+	}
+}
+
+func TestUpdateAndDeleteUnknownMission(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := asUser(t, handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store})), 1, models.RoleAdmin)
+
+	put := httptest.NewRequest(http.MethodPut, "/missions/999", strings.NewReader(`{"title": "Ghost", "points": 10}`))
+	put.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, put)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("PUT: expected 404, got %d", rec.Code)
+	}
+
+	del := httptest.NewRequest(http.MethodDelete, "/missions/999", nil)
+	del.Header.Set("If-Match", "*")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, del)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestMissionConditionalRequests(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := asUser(t, handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store})), 1, models.RoleAdmin)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missions/1", nil))
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || tag != `"1"` {
+		t.Fatalf("GET: expected 200 with ETag \"1\", got %d %q", rec.Code, tag)
+	}
+
+	get := httptest.NewRequest(http.MethodGet, "/missions/1", nil)
+	get.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, get)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("GET If-None-Match: expected 304, got %d", rec.Code)
+	}
+
+	body := `{"title": "Updated", "points": 10}`
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/missions/1", strings.NewReader(body)))
+	if rec.Code != http.StatusPreconditionRequired {
+		t.Errorf("PUT without If-Match: expected 428, got %d", rec.Code)
+	}
+
+	put := httptest.NewRequest(http.MethodPut, "/missions/1", strings.NewReader(body))
+	put.Header.Set("If-Match", tag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, put)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("PUT: expected 200 with ETag \"2\", got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+
+	// The first tag is now stale, so a second writer must not win.
+	put = httptest.NewRequest(http.MethodPut, "/missions/1", strings.NewReader(body))
+	put.Header.Set("If-Match", tag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, put)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT stale: expected 412, got %d", rec.Code)
+	}
+	var p handler.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || p.Code != handler.CodePreconditionFailed {
+		t.Errorf("PUT stale: unexpected problem %+v (%v)", p, err)
+	}
+
+	del := httptest.NewRequest(http.MethodDelete, "/missions/1", nil)
+	del.Header.Set("If-Match", tag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, del)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE stale: expected 412, got %d", rec.Code)
+	}
+}
+
+func TestPatchMission(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := asUser(t, handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store})), 1, models.RoleAdmin)
+
+	patch := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/missions/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("If-Match", "*")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := patch("application/merge-patch+json", `{"title": "Patched"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var got models.Mission
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if got.Title != "Patched" || got.Points != 100 {
+		t.Errorf("expected title patched and points kept, got %+v", got)
+	}
+
+	if rec := patch("application/merge-patch+json", `{"points": "many"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("wrong type: expected 422, got %d", rec.Code)
+	}
+	if rec := patch("application/merge-patch+json", `{"owner": "me"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unknown field: expected 422, got %d", rec.Code)
+	}
+	if rec := patch("application/merge-patch+json", `[1, 2]`); rec.Code != http.StatusBadRequest {
+		t.Errorf("non-object patch: expected 400, got %d", rec.Code)
+	}
+	if rec := patch("text/plain", `{"title": "Patched"}`); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain: expected 415, got %d", rec.Code)
+	}
+}
+
+func TestTrashRestoreAndPurge(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store}))
+
+	do := func(method, path string, role models.Role) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("If-Match", "*")
+		if role != "" {
+			req.Header.Set("Authorization", "Bearer "+mintToken(t, 1, role))
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodDelete, "/missions/1", models.RoleAdmin); rec.Code != http.StatusNoContent {
+		t.Fatalf("DELETE: expected 204, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/missions/1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted: expected 404, got %d", rec.Code)
+	}
+
+	rec := do(http.MethodGet, "/missions/trash", models.RoleAdmin)
+	var trash models.MissionPage
+	if err := json.NewDecoder(rec.Body).Decode(&trash); err != nil || len(trash.Items) != 1 || trash.Items[0].ID != 1 {
+		t.Errorf("trash: expected mission 1, got %+v (%v)", trash.Items, err)
+	}
+
+	if rec := do(http.MethodPost, "/missions/1/restore", models.RoleAdmin); rec.Code != http.StatusOK {
+		t.Errorf("restore: expected 200, got %d", rec.Code)
+	}
+
+	if rec := do(http.MethodDelete, "/admin/missions/1", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous purge: expected 401, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/admin/missions/1", models.RoleAuthor); rec.Code != http.StatusForbidden {
+		t.Errorf("author purge: expected 403, got %d", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/admin/missions/1", models.RoleAdmin); rec.Code != http.StatusNoContent {
+		t.Errorf("purge: expected 204, got %d", rec.Code)
+	}
+}
+
+func TestAuthentication(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store}
+	router := handler.NewRouter(newTestHandler(t, svc))
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(http.MethodGet, "/missions/1", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("anonymous GET: expected 200, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/missions", "", `{"title": "Two Sum", "points": 10}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous POST: expected 401, got %d", rec.Code)
+	}
+	if rec := send(http.MethodGet, "/missions/1", "not-a-jwt", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("invalid token: expected 401, got %d", rec.Code)
+	}
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   "1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("could not sign token: %v", err)
+	}
+	if rec := send(http.MethodPost, "/missions", expired, `{"title": "Two Sum", "points": 10}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expired token: expected 401, got %d", rec.Code)
+	}
+
+	if rec := send(http.MethodPost, "/missions/1/complete", mintToken(t, 1, models.RolePlayer), ""); rec.Code != http.StatusForbidden {
+		t.Errorf("player completing directly: expected 403, got %d", rec.Code)
+	}
+	token := mintToken(t, 1, models.RoleAuthor)
+	if rec := send(http.MethodPost, "/missions/1/complete", token, `{"user_id": 2}`); rec.Code != http.StatusForbidden {
+		t.Errorf("completing for another user: expected 403, got %d", rec.Code)
+	}
+	rec := send(http.MethodPost, "/missions/1/complete", token, "")
+	var profile models.Profile
+	if err := json.NewDecoder(rec.Body).Decode(&profile); err != nil || rec.Code != http.StatusOK || profile.TotalPoints != 100 {
+		t.Errorf("completing as the token user: got %d %+v (%v)", rec.Code, profile, err)
+	}
+}
+
+func TestMissionAuthorization(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store}))
+
+	send := func(method, path string, userID int, role models.Role, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+mintToken(t, userID, role))
+		req.Header.Set("If-Match", "*")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	body := `{"title": "Two Sum", "points": 10}`
+
+	rec := send(http.MethodPost, "/missions", 1, models.RolePlayer, body)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("player create: expected 403, got %d", rec.Code)
+	}
+	var p handler.Problem
+	if err := json.NewDecoder(rec.Body).Decode(&p); err != nil || p.Code != handler.CodeForbidden {
+		t.Errorf("player create: unexpected problem %+v (%v)", p, err)
+	}
+
+	rec = send(http.MethodPost, "/missions", 5, models.RoleAuthor, body)
+	var created models.Mission
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil || rec.Code != http.StatusCreated || created.AuthorID != 5 {
+		t.Fatalf("author create: got %d %+v (%v)", rec.Code, created, err)
+	}
+	path := "/missions/" + strconv.Itoa(created.ID)
+
+	if rec := send(http.MethodPut, path, 5, models.RoleAuthor, body); rec.Code != http.StatusOK {
+		t.Errorf("author updating own mission: expected 200, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPut, path, 6, models.RoleAuthor, body); rec.Code != http.StatusForbidden {
+		t.Errorf("author updating another's mission: expected 403, got %d", rec.Code)
+	}
+	if rec := send(http.MethodDelete, "/missions/1", 5, models.RoleAuthor, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("author deleting a seeded mission: expected 403, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPut, path, 7, models.RoleAdmin, body); rec.Code != http.StatusOK {
+		t.Errorf("admin update: expected 200, got %d", rec.Code)
+	}
+	if rec := send(http.MethodGet, path+"/tests", 1, models.RolePlayer, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("player reading tests: expected 403, got %d", rec.Code)
+	}
+}
+
+func TestLocalAccounts(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Accounts: store}
+	h := newTestHandler(t, svc)
+	signer, err := auth.NewSigner(auth.Config{HMACSecret: testSecret}, time.Minute)
+	if err != nil {
+		t.Fatalf("could not create signer: %v", err)
+	}
+	h.Tokens = signer
+	router := handler.NewRouter(h)
+
+	post := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	tokens := func(rec *httptest.ResponseRecorder) handler.TokenResponse {
+		t.Helper()
+		var resp handler.TokenResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("expected tokens, got %d (%v)", rec.Code, err)
+		}
+		return resp
+	}
+
+	credentials := `{"username": "alice", "password": "correct horse"}`
+	if rec := post("/auth/register", "", credentials); rec.Code != http.StatusCreated {
+		t.Fatalf("register: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	login := tokens(post("/auth/login", "", credentials))
+	if login.TokenType != "Bearer" || login.ExpiresIn != 60 {
+		t.Errorf("unexpected token response %+v", login)
+	}
+
+	// The issued access token is accepted by the authentication middleware;
+	// new accounts are players, who may not complete missions directly.
+	if rec := post("/missions/1/complete", login.AccessToken, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("complete with issued token: expected 403, got %d", rec.Code)
+	}
+
+	refreshed := tokens(post("/auth/refresh", "", `{"refresh_token": "`+login.RefreshToken+`"}`))
+	if rec := post("/auth/logout", "", `{"refresh_token": "`+refreshed.RefreshToken+`"}`); rec.Code != http.StatusNoContent {
+		t.Errorf("logout: expected 204, got %d", rec.Code)
+	}
+	if rec := post("/auth/refresh", "", `{"refresh_token": "`+refreshed.RefreshToken+`"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	store := service.NewInMemoryStore()
+	bot, err := store.AddUser(context.Background(), models.User{Username: "ci-bot", Role: models.RoleAuthor})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	svc := &service.MissionService{Store: store, Users: store, Streaks: store, APIKeys: store}
+	router := handler.NewRouter(newTestHandler(t, svc))
+
+	send := func(method, path, authorization, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", authorization)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	bearer := "Bearer " + mintToken(t, bot.ID, models.RoleAuthor)
+
+	rec := send(http.MethodPost, "/api-keys", bearer, `{"name": "ci", "scopes": ["missions:read", "submissions:write"]}`)
+	var created handler.APIKeyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil || rec.Code != http.StatusCreated || created.Key == "" {
+		t.Fatalf("create key: got %d %+v (%v)", rec.Code, created, err)
+	}
+	apiKey := "ApiKey " + created.Key
+
+	if rec := send(http.MethodGet, "/missions", apiKey, ""); rec.Code != http.StatusOK {
+		t.Errorf("read with key: expected 200, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/missions/1/complete", apiKey, ""); rec.Code != http.StatusOK {
+		t.Errorf("complete with key: expected 200, got %d", rec.Code)
+	}
+	if rec := send(http.MethodPost, "/missions", apiKey, `{"title": "Two Sum", "points": 10}`); rec.Code != http.StatusForbidden {
+		t.Errorf("create mission without missions:write: expected 403, got %d", rec.Code)
+	}
+	if rec := send(http.MethodGet, "/api-keys", apiKey, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("list keys with key: expected 403, got %d", rec.Code)
+	}
+	// Routes without a scope are closed to keys before their handler runs.
+	rec = send(http.MethodDelete, "/api-keys/"+strconv.Itoa(created.ID), apiKey, "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "not available to API keys") {
+		t.Errorf("unscoped route with key: expected 403, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := send(http.MethodGet, "/users/"+strconv.Itoa(bot.ID)+"/profile", apiKey, ""); rec.Code != http.StatusOK {
+		t.Errorf("profile with missions:read: expected 200, got %d", rec.Code)
+	}
+	if rec := send(http.MethodGet, "/users/"+strconv.Itoa(bot.ID)+"/streak", apiKey, ""); rec.Code != http.StatusOK {
+		t.Errorf("streak with missions:read: expected 200, got %d", rec.Code)
+	}
+	rec = send(http.MethodPut, "/users/"+strconv.Itoa(bot.ID)+"/timezone", apiKey, `{"timezone": "UTC"}`)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "not available to API keys") {
+		t.Errorf("timezone with key: expected 403, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := send(http.MethodDelete, "/api-keys/"+strconv.Itoa(created.ID), bearer, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke key: expected 204, got %d", rec.Code)
+	}
+	rec = send(http.MethodGet, "/missions", apiKey, "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") != "ApiKey" {
+		t.Errorf("revoked key: expected 401 with challenge, got %d %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+
+	// Without an API key store every key is rejected.
+	router = handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store, Users: store}))
+	if rec := send(http.MethodGet, "/missions", apiKey, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("key without a store: expected 401, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	hour := time.Hour
+	limiter := handler.NewRateLimiter(handler.RateLimitConfig{
+		Default:        service.RateLimit{Requests: 2, Per: hour},
+		Routes:         map[string]service.RateLimit{"GET /missions/{id}": {Requests: 1, Per: hour}},
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.1/32")},
+	}, service.NewMemoryLimiterStore())
+	t.Cleanup(limiter.Stop)
+	h := newTestHandler(t, &service.MissionService{Store: service.NewInMemoryStore()})
+	h.Limiter = limiter
+	router := handler.NewRouter(h)
+
+	send := func(path, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		rec := send("/missions", "198.51.100.1:1000", "")
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Remaining") != remaining {
+			t.Errorf("request %d: got %d with headers %v", i+1, rec.Code, rec.Header())
+		}
+	}
+	// A spoofed X-Forwarded-For from an untrusted address is ignored.
+	rec := send("/missions", "198.51.100.1:1001", "203.0.113.9")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("exhausted bucket: expected 429, got %d", rec.Code)
+	}
+	if retry, _ := strconv.Atoi(rec.Header().Get("Retry-After")); retry < 1700 || retry > 1800 {
+		t.Errorf("expected Retry-After of about half an hour, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Behind trusted proxies the client is the rightmost untrusted hop.
+	if rec := send("/missions", "10.0.0.1:1000", "198.51.100.1, 192.0.2.1"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("forwarded client: expected 429, got %d", rec.Code)
+	}
+	if rec := send("/missions", "10.0.0.1:1000", "203.0.113.9"); rec.Code != http.StatusOK {
+		t.Errorf("other forwarded client: expected 200, got %d", rec.Code)
+	}
+
+	// Routes with their own limit have their own buckets.
+	if rec := send("/missions/1", "198.51.100.1:1000", ""); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "1" {
+		t.Errorf("route limit: got %d with limit %q", rec.Code, rec.Header().Get("RateLimit-Limit"))
+	}
+	if rec := send("/missions/1", "198.51.100.1:1000", ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("route limit exhausted: expected 429, got %d", rec.Code)
+	}
+
+	// Authenticated callers are limited per user, not per address.
+	req := httptest.NewRequest(http.MethodGet, "/missions", nil)
+	req.RemoteAddr = "198.51.100.1:1000"
+	rec = httptest.NewRecorder()
+	asUser(t, router, 1, models.RolePlayer).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("authenticated caller: expected 200, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiterKeepsBucketsUntilRefilled(t *testing.T) {
+	limiter := handler.NewRateLimiter(handler.RateLimitConfig{
+		Default:     service.RateLimit{Requests: 1, Per: time.Hour},
+		IdleTimeout: time.Millisecond,
+	}, service.NewMemoryLimiterStore())
+	t.Cleanup(limiter.Stop)
+	h := newTestHandler(t, &service.MissionService{Store: service.NewInMemoryStore()})
+	h.Limiter = limiter
+	router := handler.NewRouter(h)
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		if i > 0 {
+			// Far beyond IdleTimeout, but the bucket has not refilled yet.
+			time.Sleep(20 * time.Millisecond)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missions", nil))
+		if rec.Code != want {
+			t.Errorf("request %d: expected %d, got %d", i+1, want, rec.Code)
+		}
+	}
+}
+
+func TestRateLimiterThrottlesInvalidCredentials(t *testing.T) {
+	limiter := handler.NewRateLimiter(handler.RateLimitConfig{
+		Default: service.RateLimit{Requests: 100, Per: time.Hour},
+		Auth:    service.RateLimit{Requests: 2, Per: time.Hour},
+	}, service.NewMemoryLimiterStore())
+	t.Cleanup(limiter.Stop)
+	h := newTestHandler(t, &service.MissionService{Store: service.NewInMemoryStore()})
+	h.Limiter = limiter
+	router := handler.NewRouter(h)
+
+	send := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/missions", nil)
+		req.RemoteAddr = "198.51.100.1:1000"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for i, want := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if got := send("ApiKey guess-" + strconv.Itoa(i)); got != want {
+			t.Errorf("attempt %d: expected %d, got %d", i+1, want, got)
+		}
+	}
+	if got := send("Bearer " + mintToken(t, 1, models.RolePlayer)); got != http.StatusTooManyRequests {
+		t.Errorf("valid token from the same address: expected 429, got %d", got)
+	}
+	if got := send(""); got != http.StatusOK {
+		t.Errorf("anonymous request: expected 200, got %d", got)
+	}
+}
+
+func TestGetGamificationRules(t *testing.T) {
+	router := handler.NewRouter(newTestHandler(t, &service.MissionService{Store: service.NewInMemoryStore()}))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/gamification/rules", nil))
+
+	var rules models.GamificationRules
+	if err := json.NewDecoder(rec.Body).Decode(&rules); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("expected rules, got %d (%v)", rec.Code, err)
+	}
+	if !reflect.DeepEqual(rules, service.DefaultRules()) {
+		t.Errorf("expected the default rules, got %+v", rules)
+	}
+}
+
+func TestLeaderboards(t *testing.T) {
+	store := service.NewInMemoryStore()
+	ctx := context.Background()
+	if err := store.AddCompletion(ctx, 1, 1); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+	router := handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store, Users: store, Leaderboards: store}))
+
+	for _, path := range []string{"/leaderboards/global", "/leaderboards/weekly", "/leaderboards/category/basics"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		var page models.LeaderboardPage
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected a page, got %d (%v)", path, rec.Code, err)
+		}
+		if len(page.Items) != 1 || page.Items[0].Rank != 1 || page.Items[0].UserID != 1 {
+			t.Errorf("%s: unexpected entries %+v", path, page.Items)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1/rank?period=weekly", nil))
+	var entry models.LeaderboardEntry
+	if err := json.NewDecoder(rec.Body).Decode(&entry); err != nil || rec.Code != http.StatusOK || entry.Rank != 1 {
+		t.Errorf("expected rank 1, got %d %+v (%v)", rec.Code, entry, err)
+	}
+
+	for path, want := range map[string]int{
+		"/users/1/rank?period=monthly":   http.StatusBadRequest,
+		"/users/999/rank":                http.StatusNotFound,
+		"/leaderboards/global?limit=0":   http.StatusBadRequest,
+		"/leaderboards/global?cursor=!!": http.StatusUnprocessableEntity,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, rec.Code)
+		}
+	}
+}
+
+func TestStreakEndpoints(t *testing.T) {
+	store := service.NewInMemoryStore()
+	router := handler.NewRouter(newTestHandler(t, &service.MissionService{Store: store, Users: store, Streaks: store}))
+
+	cases := []struct {
+		name   string
+		userID int
+		body   string
+		want   int
+	}{
+		{"own timezone", 1, `{"timezone": "Asia/Tokyo"}`, http.StatusOK},
+		{"unknown timezone", 1, `{"timezone": "Asia/Atlantis"}`, http.StatusUnprocessableEntity},
+		{"another user", 2, `{"timezone": "Asia/Tokyo"}`, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/users/"+strconv.Itoa(tc.userID)+"/timezone", strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		asUser(t, router, 1, models.RolePlayer).ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.name, tc.want, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/users/1/timezone", strings.NewReader(`{"timezone": "UTC"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous: expected 401, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1/streak", nil))
+	var streak models.Streak
+	if err := json.NewDecoder(rec.Body).Decode(&streak); err != nil || rec.Code != http.StatusOK || streak.Timezone != "Asia/Tokyo" {
+		t.Errorf("expected the streak in Asia/Tokyo, got %d %+v (%v)", rec.Code, streak, err)
+	}
+}
+
+func TestSeasons(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Leaderboards: store, Seasons: store}
+	if err := svc.RolloverSeasons(context.Background()); err != nil {
+		t.Fatalf("could not start season: %v", err)
+	}
+	if err := store.AddCompletion(context.Background(), 1, 1); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+	router := handler.NewRouter(newTestHandler(t, svc))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/seasons", nil))
+	var seasons []models.Season
+	if err := json.NewDecoder(rec.Body).Decode(&seasons); err != nil || rec.Code != http.StatusOK || len(seasons) != 1 {
+		t.Fatalf("expected one season, got %d %+v (%v)", rec.Code, seasons, err)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/seasons/"+strconv.Itoa(seasons[0].ID)+"/leaderboard", nil))
+	var page models.LeaderboardPage
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil || rec.Code != http.StatusOK || len(page.Items) != 1 || page.Items[0].Points != 100 {
+		t.Errorf("expected the live season leaderboard, got %d %+v (%v)", rec.Code, page, err)
+	}
+
+	for path, want := range map[string]int{
+		"/seasons/999/leaderboard":       http.StatusNotFound,
+		"/seasons/1/leaderboard?limit=x": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// GetGlobalLeaderboard godoc
+// @Summary Общий рейтинг
+// @Description Рейтинг пользователей по очкам за все выполненные задания. Пользователи с равными очками делят место.
+// @Tags leaderboards
+// @Produce json
+// @Param cursor query string false "Курсор следующей страницы (next_cursor)"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.LeaderboardPage
+// @Failure 400 {object} Problem "Invalid query"
+// @Failure 422 {object} Problem "Invalid cursor"
+// @Failure 500 {object} Problem "Failed to get leaderboard"
+// @Router /leaderboards/global [get]
+func (h *Handler) GetGlobalLeaderboard(w http.ResponseWriter, r *http.Request) {
+	h.writeLeaderboard(w, r, service.LeaderboardQuery{})
+}
+
+// GetWeeklyLeaderboard godoc
+// @Summary Рейтинг недели
+// @Description Рейтинг по заданиям, выполненным с начала текущей недели (понедельник 00:00 UTC)
+// @Tags leaderboards
+// @Produce json
+// @Param cursor query string false "Курсор следующей страницы (next_cursor)"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.LeaderboardPage
+// @Failure 400 {object} Problem "Invalid query"
+// @Failure 422 {object} Problem "Invalid cursor"
+// @Failure 500 {object} Problem "Failed to get leaderboard"
+// @Router /leaderboards/weekly [get]
+func (h *Handler) GetWeeklyLeaderboard(w http.ResponseWriter, r *http.Request) {
+	h.writeLeaderboard(w, r, service.LeaderboardQuery{Weekly: true})
+}
+
+// GetCategoryLeaderboard godoc
+// @Summary Рейтинг категории
+// @Description Рейтинг по очкам за задания одной категории
+// @Tags leaderboards
+// @Produce json
+// @Param name path string true "Категория"
+// @Param cursor query string false "Курсор следующей страницы (next_cursor)"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.LeaderboardPage
+// @Failure 400 {object} Problem "Invalid query"
+// @Failure 422 {object} Problem "Invalid cursor"
+// @Failure 500 {object} Problem "Failed to get leaderboard"
+// @Router /leaderboards/category/{name} [get]
+func (h *Handler) GetCategoryLeaderboard(w http.ResponseWriter, r *http.Request) {
+	h.writeLeaderboard(w, r, service.LeaderboardQuery{Category: mux.Vars(r)["name"]})
+}
+
+func (h *Handler) writeLeaderboard(w http.ResponseWriter, r *http.Request, q service.LeaderboardQuery) {
+	limit, ok := leaderboardLimit(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.Service.Leaderboard(r.Context(), q, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeError(w, r, err, "Failed to get leaderboard")
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// leaderboardLimit parses the optional limit query parameter, writing a
+// problem response when it is invalid.
+func leaderboardLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid query: limit must be a positive integer")
+		return 0, false
+	}
+	return n, true
+}
+
+// GetUserRank godoc
+// @Summary Место пользователя в рейтинге
+// @Description Возвращает место пользователя в общем рейтинге, в рейтинге недели (period=weekly)
+// @Description или категории (category). У пользователей без очков место равно 0.
+// @Tags leaderboards
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param period query string false "Период" Enums(all, weekly)
+// @Param category query string false "Категория"
+// @Success 200 {object} models.LeaderboardEntry
+// @Failure 400 {object} Problem "Invalid ID or period"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Failed to get rank"
+// @Router /users/{id}/rank [get]
+func (h *Handler) GetUserRank(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	q := service.LeaderboardQuery{Category: r.URL.Query().Get("category")}
+	switch r.URL.Query().Get("period") {
+	case "", "all":
+	case "weekly":
+		q.Weekly = true
+	default:
+		writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, "Invalid query: period must be all or weekly")
+		return
+	}
+
+	entry, err := h.Service.UserRank(r.Context(), id, q)
+	if err != nil {
+		writeError(w, r, err, "Failed to get rank")
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
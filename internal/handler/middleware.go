@@ -1,23 +1,25 @@
 package handler
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"log/slog"
-	"net"
 	"net/http"
-	"sync"
+	"strings"
 	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
 )
 
 type statusRecorder struct {
 	http.ResponseWriter
 	status int
 }
-type rateLimiter struct {
-	visits map[string]int
-	mu     sync.Mutex
-	limit  int
-	window time.Duration
-}
+type requestIDKey struct{}
 
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -26,7 +28,8 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(rec, r)
 
-		slog.Info("HTTP request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start))
+		slog.Info("HTTP request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start),
+			"request_id", RequestIDFromContext(r.Context()))
 	})
 }
 
@@ -43,19 +46,46 @@ func RecoverMiddleware(next http.Handler) http.Handler {
 					"error", rec,
 					"path", r.URL.Path,
 					"method", r.Method,
+					"request_id", RequestIDFromContext(r.Context()),
 				)
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Internal Server Error")
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
 
+// RequestIDMiddleware propagates the X-Request-ID header of the request, or
+// generates a new ID, and makes it available through RequestIDFromContext.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFromContext returns the ID assigned by RequestIDMiddleware or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Idempotency-Key, If-Match, If-None-Match, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, RateLimit-Limit, RateLimit-Policy, RateLimit-Remaining, RateLimit-Reset, Retry-After, X-Request-ID")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -64,42 +94,92 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func NewRateLimiter(limit int, window time.Duration) *rateLimiter {
-	rl := &rateLimiter{
-		visits: make(map[string]int),
-		limit:  limit,
-		window: window,
-	}
-
-	go func() {
-		for {
-			time.Sleep(rl.window)
-			rl.mu.Lock()
-			rl.visits = make(map[string]int)
-			rl.mu.Unlock()
+// authenticate verifies the bearer token or API key of requests that carry
+// one and stores its principal in the request context. Requests without
+// credentials continue anonymously; invalid ones are rejected with 401.
+func (h *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if key, ok := strings.CutPrefix(header, "ApiKey "); ok {
+			principal, err := h.Service.AuthenticateAPIKey(r.Context(), key)
+			if errors.Is(err, service.ErrUnauthorized) {
+				w.Header().Set("WWW-Authenticate", "ApiKey")
+				writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "Invalid or revoked API key")
+				return
+			}
+			if err != nil {
+				writeError(w, r, err, "Failed to check API key")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(service.WithPrincipal(r.Context(), principal)))
+			return
 		}
-	}()
-	return rl
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || h.Auth == nil {
+			writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "Unsupported authorization")
+			return
+		}
+		principal, err := h.Auth.Verify(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "Invalid or expired token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(service.WithPrincipal(r.Context(), principal)))
+	})
 }
 
-func (rl *rateLimiter) MiddleWare(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		host, _, err := net.SplitHostPort(r.RemoteAddr)
-		if err != nil {
-			slog.Warn("invalid RemoteAddr", "addr", r.RemoteAddr)
-			http.Error(w, "Invalid address", http.StatusInternalServerError)
+// requireAuth rejects anonymous requests with 401.
+func requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := service.PrincipalFromContext(r.Context()); !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "Authentication required")
 			return
 		}
-		ip := host
+		next(w, r)
+	}
+}
 
-		rl.mu.Lock()
-		rl.visits[ip]++
-		count := rl.visits[ip]
-		rl.mu.Unlock()
+// requireRole returns a wrapper admitting only authenticated callers with
+// one of roles. Anonymous requests get 401, other roles 403.
+func requireRole(roles ...models.Role) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return requireAuth(func(w http.ResponseWriter, r *http.Request) {
+			p, _ := service.PrincipalFromContext(r.Context())
+			if !p.HasRole(roles...) {
+				writeProblem(w, r, http.StatusForbidden, CodeForbidden, "Insufficient role")
+				return
+			}
+			next(w, r)
+		})
+	}
+}
 
-		if count >= rl.limit {
-			slog.Warn("rate limit exceeded", "ip", ip, "count", count)
-			http.Error(w, "Rate Limit Exceeded", http.StatusTooManyRequests)
+// routeScopes maps routes to the scope an API key needs to call them.
+type routeScopes map[*mux.Route]models.Scope
+
+// middleware rejects API keys without the scope of the matched route with
+// 403. Routes without a scope are closed to API keys, so a new route has to
+// opt in to them. Anonymous callers and token-authenticated users pass through.
+func (s routeScopes) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := service.PrincipalFromContext(r.Context())
+		if !ok || p.APIKeyID == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		scope, scoped := s[mux.CurrentRoute(r)]
+		if !scoped {
+			writeProblem(w, r, http.StatusForbidden, CodeForbidden, "Route not available to API keys")
+			return
+		}
+		if !p.HasScope(scope) {
+			writeProblem(w, r, http.StatusForbidden, CodeForbidden, "API key lacks scope "+string(scope))
 			return
 		}
 		next.ServeHTTP(w, r)
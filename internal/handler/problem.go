@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// Stable machine-readable error codes carried in Problem.Code.
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidation           = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodeAlreadyCompleted     = "already_completed"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	// CodePreconditionFailed means If-Match did not match the current version.
+	CodePreconditionFailed = "precondition_failed"
+	// CodePreconditionRequired means a write was sent without If-Match.
+	CodePreconditionRequired = "precondition_required"
+	CodeTimeout              = "timeout"
+	CodeRateLimited          = "rate_limited"
+	CodeInternal             = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details body, extended with a stable
+// error code, the request ID and, for validation failures, the rejected fields.
+type Problem struct {
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Status    int                  `json:"status"`
+	Detail    string               `json:"detail,omitempty"`
+	Instance  string               `json:"instance,omitempty"`
+	Code      string               `json:"code"`
+	RequestID string               `json:"request_id,omitempty"`
+	Errors    []service.FieldError `json:"errors,omitempty"`
+}
+
+// writeProblem writes an application/problem+json response.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	writeProblemBody(w, r, Problem{Status: status, Code: code, Detail: detail})
+}
+
+func writeProblemBody(w http.ResponseWriter, r *http.Request, p Problem) {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	p.Instance = r.URL.Path
+	p.RequestID = RequestIDFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// writeError maps err to a problem response. Domain errors keep their
+// message as detail; unexpected errors are logged and answered with
+// fallback so that internals do not leak to clients.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		writeProblemBody(w, r, Problem{
+			Status: http.StatusUnprocessableEntity,
+			Code:   CodeValidation,
+			Detail: "One or more fields are invalid",
+			Errors: validationErr.Fields,
+		})
+	case errors.Is(err, service.ErrValidation):
+		writeProblem(w, r, http.StatusUnprocessableEntity, CodeValidation, err.Error())
+	case errors.Is(err, errBodyTooLarge):
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, CodePayloadTooLarge, err.Error())
+	case errors.Is(err, errMalformedBody):
+		writeProblem(w, r, http.StatusBadRequest, CodeBadRequest, err.Error())
+	case errors.Is(err, service.ErrUnauthorized):
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		writeProblem(w, r, http.StatusForbidden, CodeForbidden, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, service.ErrAlreadyCompleted):
+		writeProblem(w, r, http.StatusConflict, CodeAlreadyCompleted, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		writeProblem(w, r, http.StatusConflict, CodeConflict, err.Error())
+	case errors.Is(err, service.ErrPreconditionFailed):
+		writeProblem(w, r, http.StatusPreconditionFailed, CodePreconditionFailed, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		slog.Warn("request timed out", "path", r.URL.Path, "request_id", RequestIDFromContext(r.Context()))
+		writeProblem(w, r, http.StatusGatewayTimeout, CodeTimeout, "Request timeout")
+	default:
+		slog.Error(fallback, "error", err, "path", r.URL.Path, "request_id", RequestIDFromContext(r.Context()))
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, fallback)
+	}
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// RateLimitConfig configures NewRateLimiter.
+type RateLimitConfig struct {
+	// Default applies to every route without an entry in Routes.
+	Default service.RateLimit
+	// Routes overrides the limit of single routes, keyed by method and path
+	// template without patterns, e.g. "POST /missions/{id}/submissions".
+	// Each of them has its own buckets.
+	Routes map[string]service.RateLimit
+	// Auth limits requests that carry credentials per client address before
+	// the credentials are checked, so that invalid tokens and API keys are
+	// throttled too. Zero disables it.
+	Auth service.RateLimit
+	// TrustedProxies are the addresses whose X-Forwarded-For header is
+	// believed. Requests from other addresses are keyed by RemoteAddr.
+	TrustedProxies []netip.Prefix
+	// IdleTimeout is how long an unused bucket is kept; 10 minutes when
+	// zero. It is raised to the longest Per of the limits, so that only
+	// buckets that would have refilled anyway are dropped.
+	IdleTimeout time.Duration
+}
+
+// RateLimiter limits requests with token buckets per caller and route.
+// Authenticated requests are keyed by user or API key, anonymous ones by
+// client address. When the store fails the request is let through and a
+// warning logged. Call Stop to end the eviction of idle buckets.
+type RateLimiter struct {
+	cfg   RateLimitConfig
+	store service.LimiterStore
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRateLimiter returns a limiter keeping its buckets in store. With a
+// store shared by all instances, such as Postgres, limits hold across them.
+func NewRateLimiter(cfg RateLimitConfig, store service.LimiterStore) *RateLimiter {
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 10 * time.Minute
+	}
+	cfg.IdleTimeout = max(cfg.IdleTimeout, cfg.Default.Per, cfg.Auth.Per)
+	for _, limit := range cfg.Routes {
+		cfg.IdleTimeout = max(cfg.IdleTimeout, limit.Per)
+	}
+	rl := &RateLimiter{
+		cfg:   cfg,
+		store: store,
+		stop:  make(chan struct{}),
+	}
+	go rl.evictIdle()
+	return rl
+}
+
+// Stop ends the background eviction. The limiter keeps limiting requests.
+func (rl *RateLimiter) Stop() {
+	rl.stopOnce.Do(func() { close(rl.stop) })
+}
+
+func (rl *RateLimiter) evictIdle() {
+	ticker := time.NewTicker(rl.cfg.IdleTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-rl.stop:
+			return
+		case <-ticker.C:
+			if err := rl.store.EvictIdle(context.Background(), rl.cfg.IdleTimeout); err != nil {
+				slog.Warn("could not evict idle rate limit buckets", "error", err)
+			}
+		}
+	}
+}
+
+// Middleware limits requests of matched routes and reports the state of
+// the caller's bucket in RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset. Rejected requests get 429 with Retry-After.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeKey(r)
+		limit, ok := rl.cfg.Routes[route]
+		if !ok {
+			limit, route = rl.cfg.Default, ""
+		}
+		if limit.Requests <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		caller, err := rl.caller(r)
+		if err != nil {
+			slog.Warn("invalid RemoteAddr", "addr", r.RemoteAddr)
+			writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Invalid address")
+			return
+		}
+		if rl.take(w, r, caller, route, limit) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// AuthMiddleware applies the Auth limit to requests with an Authorization
+// header. It runs in front of authentication, which it keeps invalid
+// credentials from reaching once the client's bucket is empty.
+func (rl *RateLimiter) AuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rl.cfg.Auth.Requests <= 0 || r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip, err := rl.clientIP(r)
+		if err != nil {
+			slog.Warn("invalid RemoteAddr", "addr", r.RemoteAddr)
+			writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "Invalid address")
+			return
+		}
+		if rl.take(w, r, "ip:"+ip.String(), "authorization", rl.cfg.Auth) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// take takes a token from the caller's bucket for route and reports
+// whether the request may proceed. It sets the RateLimit headers and
+// answers rejected requests itself.
+func (rl *RateLimiter) take(w http.ResponseWriter, r *http.Request, caller, route string, limit service.RateLimit) bool {
+	d, err := rl.store.Take(r.Context(), caller+" "+route, limit)
+	if err != nil {
+		// A failing store must not take the API down with it.
+		slog.Warn("rate limit store failed, request not limited", "error", err)
+		return true
+	}
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	h.Set("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("RateLimit-Reset", ceilSeconds(d.Reset))
+	h.Set("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+ceilSeconds(limit.Per))
+	if !d.Allowed {
+		slog.Warn("rate limit exceeded", "caller", caller, "route", route)
+		h.Set("Retry-After", ceilSeconds(d.RetryAfter))
+		writeProblem(w, r, http.StatusTooManyRequests, CodeRateLimited, "Rate Limit Exceeded")
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// caller returns the bucket key of the request's sender.
+func (rl *RateLimiter) caller(r *http.Request) (string, error) {
+	if p, ok := service.PrincipalFromContext(r.Context()); ok {
+		if p.APIKeyID != 0 {
+			return "key:" + strconv.Itoa(p.APIKeyID), nil
+		}
+		return "user:" + strconv.Itoa(p.UserID), nil
+	}
+	ip, err := rl.clientIP(r)
+	if err != nil {
+		return "", err
+	}
+	return "ip:" + ip.String(), nil
+}
+
+// clientIP returns RemoteAddr, or for requests from a trusted proxy the
+// rightmost X-Forwarded-For entry that is not a trusted proxy itself.
+func (rl *RateLimiter) clientIP(r *http.Request) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	ip = ip.Unmap()
+	if !rl.trusted(ip) {
+		return ip, nil
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		ip = hop.Unmap()
+		if !rl.trusted(ip) {
+			break
+		}
+	}
+	return ip, nil
+}
+
+func (rl *RateLimiter) trusted(ip netip.Addr) bool {
+	for _, p := range rl.cfg.TrustedProxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+var routeVarPattern = regexp.MustCompile(`\{([^:}]+):[^}]*\}`)
+
+// routeKey returns the method and path template of the matched route with
+// variable patterns removed, e.g. "GET /missions/{id}".
+func routeKey(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+	tpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return r.Method + " " + routeVarPattern.ReplaceAllString(tpl, "{$1}")
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// maxBodyBytes limits the size of JSON request bodies.
+const maxBodyBytes = 1 << 20
+
+var (
+	errBodyTooLarge  = errors.New("request body too large")
+	errMalformedBody = errors.New("malformed request body")
+)
+
+// decodeJSON decodes the request body into v. Unknown fields and values of
+// the wrong type are reported as a *service.ValidationError.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return errMalformedBody
+	}
+	return nil
+}
+
+// isMergePatch reports whether the request body is declared as a JSON merge
+// patch. Plain application/json is accepted as well.
+func isMergePatch(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (mediaType == "application/merge-patch+json" || mediaType == "application/json")
+}
+
+// decodeMergePatch reads an RFC 7396 merge patch. The patch must be an
+// object whose members are fields of schema with values of the right type;
+// null members are allowed and remove the field.
+func decodeMergePatch(w http.ResponseWriter, r *http.Request, schema any) (map[string]any, error) {
+	var raw json.RawMessage
+	if err := decodeJSON(w, r, &raw); err != nil {
+		return nil, err
+	}
+
+	var patch map[string]any
+	if err := json.Unmarshal(raw, &patch); err != nil || patch == nil {
+		return nil, errMalformedBody
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(schema); err != nil {
+		return nil, decodeError(err)
+	}
+	return patch, nil
+}
+
+func decodeError(err error) error {
+	var (
+		maxBytesErr *http.MaxBytesError
+		typeErr     *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return errBodyTooLarge
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return &service.ValidationError{Fields: []service.FieldError{
+			{Field: typeErr.Field, Reason: "must be " + jsonTypeName(typeErr.Type)},
+		}}
+	}
+
+	// encoding/json reports unknown fields only through the error text.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &service.ValidationError{Fields: []service.FieldError{
+			{Field: strings.Trim(field, `"`), Reason: "unknown field"},
+		}}
+	}
+	return errMalformedBody
+}
+
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
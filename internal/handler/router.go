@@ -2,29 +2,91 @@ package handler
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/pseudoerr/mission-service/models"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 func NewRouter(handler *Handler) http.Handler {
 	r := mux.NewRouter()
 
-	r.HandleFunc("/missions", handler.GetMissions).Methods("GET")
-	r.HandleFunc("/missions/{id:[0-9]+}", handler.GetMissionByID).Methods("GET")
-	r.HandleFunc("/missions", handler.CreateMission).Methods("POST")
-	r.HandleFunc("/missions/{id:[0-9]+}", handler.UpdateMission).Methods("PUT")
-	r.HandleFunc("/missions/{id:[0-9]+}", handler.DeleteMission).Methods("DELETE")
-	r.HandleFunc("/profile", handler.GetProfile).Methods("GET")
+	// Players read missions and submit solutions; authors and admins
+	// manage missions, and the service limits authors to their own. Points
+	// are earned through judged submissions, so only editors may mark a
+	// mission completed directly.
+	editors := requireRole(models.RoleAuthor, models.RoleAdmin)
+	admins := requireRole(models.RoleAdmin)
+	// API keys act as their owner but only within the scope of the route;
+	// routes registered without handle are closed to them.
+	read := models.ScopeMissionsRead
+	write := models.ScopeMissionsWrite
+	submit := models.ScopeSubmissionsWrite
+	scopes := routeScopes{}
+	handle := func(scope models.Scope, path string, f http.HandlerFunc) *mux.Route {
+		route := r.HandleFunc(path, f)
+		scopes[route] = scope
+		return route
+	}
+
+	handle(read, "/missions", handler.GetMissions).Methods("GET")
+	handle(read, "/missions/search", handler.SearchMissions).Methods("GET")
+	handle(read, "/missions/trash", editors(handler.ListTrash)).Methods("GET")
+	handle(read, "/missions/{id:[0-9]+}", handler.GetMissionByID).Methods("GET")
+	handle(write, "/missions", editors(handler.CreateMission)).Methods("POST")
+	handle(write, "/missions/{id:[0-9]+}", editors(handler.UpdateMission)).Methods("PUT")
+	handle(write, "/missions/{id:[0-9]+}", editors(handler.PatchMission)).Methods("PATCH")
+	handle(write, "/missions/{id:[0-9]+}", editors(handler.DeleteMission)).Methods("DELETE")
+	handle(write, "/missions/{id:[0-9]+}/restore", editors(handler.RestoreMission)).Methods("POST")
+	handle(submit, "/missions/{id:[0-9]+}/complete", editors(handler.CompleteMission)).Methods("POST")
+	handle(read, "/missions/{id:[0-9]+}/tests", editors(handler.GetTestCases)).Methods("GET")
+	handle(write, "/missions/{id:[0-9]+}/tests", editors(handler.ReplaceTestCases)).Methods("PUT")
+	handle(submit, "/missions/{id:[0-9]+}/submissions", requireAuth(handler.SubmitSolution)).Methods("POST")
+	handle(read, "/submissions/{id:[0-9]+}", requireAuth(handler.GetSubmission)).Methods("GET")
+	handle(read, "/users/{id:[0-9]+}/profile", handler.GetUserProfile).Methods("GET")
+	handle(read, "/users/{id:[0-9]+}/rank", handler.GetUserRank).Methods("GET")
+	handle(read, "/users/{id:[0-9]+}/streak", handler.GetUserStreak).Methods("GET")
+	r.HandleFunc("/users/{id:[0-9]+}/timezone", requireAuth(handler.SetUserTimezone)).Methods("PUT")
+	handle(read, "/gamification/rules", handler.GetGamificationRules).Methods("GET")
+	handle(read, "/leaderboards/global", handler.GetGlobalLeaderboard).Methods("GET")
+	handle(read, "/leaderboards/weekly", handler.GetWeeklyLeaderboard).Methods("GET")
+	handle(read, "/leaderboards/category/{name}", handler.GetCategoryLeaderboard).Methods("GET")
+	handle(read, "/seasons", handler.ListSeasons).Methods("GET")
+	handle(read, "/seasons/{id:[0-9]+}/leaderboard", handler.GetSeasonLeaderboard).Methods("GET")
+	handle(write, "/admin/missions/{id:[0-9]+}", admins(handler.PurgeMission)).Methods("DELETE")
+	r.HandleFunc("/api-keys", requireAuth(handler.CreateAPIKey)).Methods("POST")
+	r.HandleFunc("/api-keys", requireAuth(handler.ListAPIKeys)).Methods("GET")
+	r.HandleFunc("/api-keys/{id:[0-9]+}", requireAuth(handler.RevokeAPIKey)).Methods("DELETE")
+	if handler.Tokens != nil {
+		r.HandleFunc("/auth/register", handler.Register).Methods("POST")
+		r.HandleFunc("/auth/login", handler.Login).Methods("POST")
+		r.HandleFunc("/auth/refresh", handler.Refresh).Methods("POST")
+		r.HandleFunc("/auth/logout", handler.Logout).Methods("POST")
+	}
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, "Route not found")
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeProblem(w, r, http.StatusMethodNotAllowed, CodeBadRequest, "Method not allowed")
+	})
 
-	rl := NewRateLimiter(10, time.Minute)
+	// The limiter runs after routing so that it can apply per-route limits,
+	// and inside authenticate so that it can key on the caller. Requests with
+	// credentials also pass its per-address limit in front of authenticate.
+	if handler.Limiter != nil {
+		r.Use(handler.Limiter.Middleware)
+	}
+	r.Use(scopes.middleware)
 
 	var handlerWithMiddleware http.Handler = r
-	handlerWithMiddleware = rl.MiddleWare(handlerWithMiddleware)
+	handlerWithMiddleware = handler.authenticate(handlerWithMiddleware)
+	if handler.Limiter != nil {
+		handlerWithMiddleware = handler.Limiter.AuthMiddleware(handlerWithMiddleware)
+	}
 	handlerWithMiddleware = LoggingMiddleware(handlerWithMiddleware)
 	handlerWithMiddleware = RecoverMiddleware(handlerWithMiddleware)
+	handlerWithMiddleware = RequestIDMiddleware(handlerWithMiddleware)
 	handlerWithMiddleware = CORSMiddleware(handlerWithMiddleware)
 
 	return handlerWithMiddleware
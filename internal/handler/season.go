@@ -0,0 +1,53 @@
+package handler
+
+import "net/http"
+
+// ListSeasons godoc
+// @Summary Список сезонов
+// @Description Возвращает все сезоны, начиная с последнего. Сезон длится квартал; завершенные сезоны архивируются вместе с итоговым рейтингом.
+// @Tags seasons
+// @Produce json
+// @Success 200 {array} models.Season
+// @Failure 500 {object} Problem "Failed to list seasons"
+// @Router /seasons [get]
+func (h *Handler) ListSeasons(w http.ResponseWriter, r *http.Request) {
+	seasons, err := h.Service.ListSeasons(r.Context())
+	if err != nil {
+		writeError(w, r, err, "Failed to list seasons")
+		return
+	}
+	writeJSON(w, http.StatusOK, seasons)
+}
+
+// GetSeasonLeaderboard godoc
+// @Summary Рейтинг сезона
+// @Description Рейтинг по очкам за задания, выполненные в течение сезона. Для архивного сезона возвращается
+// @Description итоговый рейтинг, зафиксированный при его завершении.
+// @Tags seasons
+// @Produce json
+// @Param id path int true "ID сезона"
+// @Param cursor query string false "Курсор следующей страницы (next_cursor)"
+// @Param limit query int false "Размер страницы (по умолчанию 20, максимум 100)"
+// @Success 200 {object} models.LeaderboardPage
+// @Failure 400 {object} Problem "Invalid ID or query"
+// @Failure 404 {object} Problem "Season not found"
+// @Failure 422 {object} Problem "Invalid cursor"
+// @Failure 500 {object} Problem "Failed to get leaderboard"
+// @Router /seasons/{id}/leaderboard [get]
+func (h *Handler) GetSeasonLeaderboard(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := leaderboardLimit(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.Service.SeasonLeaderboard(r.Context(), id, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeError(w, r, err, "Failed to get leaderboard")
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import "net/http"
+
+// SetTimezoneRequest is the body of PUT /users/{id}/timezone.
+type SetTimezoneRequest struct {
+	Timezone string `json:"timezone" example:"Europe/Berlin"`
+}
+
+// GetUserStreak godoc
+// @Summary Серия активных дней
+// @Description Возвращает текущую и самую длинную серию дней с выполненными заданиями, заморозки и часовой пояс.
+// @Description Текущая серия равна 0, если пропущено больше дней, чем покрывают заморозки.
+// @Tags profile
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} models.Streak
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 500 {object} Problem "Failed to get streak"
+// @Router /users/{id}/streak [get]
+func (h *Handler) GetUserStreak(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	streak, err := h.Service.GetStreak(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Failed to get streak")
+		return
+	}
+	writeJSON(w, http.StatusOK, streak)
+}
+
+// SetUserTimezone godoc
+// @Summary Изменить часовой пояс
+// @Description Задает часовой пояс IANA, в котором начинаются дни серии пользователя (по умолчанию UTC).
+// @Description Пользователь меняет свой пояс, администратор — любой.
+// @Tags profile
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param timezone body SetTimezoneRequest true "Часовой пояс"
+// @Success 200 {object} models.Streak
+// @Failure 400 {object} Problem "Malformed request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not allowed to change this user"
+// @Failure 404 {object} Problem "User not found"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to set timezone"
+// @Security BearerAuth
+// @Router /users/{id}/timezone [put]
+func (h *Handler) SetUserTimezone(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req SetTimezoneRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	streak, err := h.Service.SetStreakTimezone(r.Context(), id, req.Timezone)
+	if err != nil {
+		writeError(w, r, err, "Failed to set timezone")
+		return
+	}
+	writeJSON(w, http.StatusOK, streak)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// SubmitSolutionRequest is the body of POST /missions/{id}/submissions.
+type SubmitSolutionRequest struct {
+	// UserID defaults to the authenticated user and must match it when set.
+	UserID   int    `json:"user_id,omitempty"`
+	Language string `json:"language"`
+	Source   string `json:"source"`
+}
+
+// maxSourceBytes limits the size of submitted source code.
+const maxSourceBytes = 64 << 10
+
+func (req SubmitSolutionRequest) validate() error {
+	var fields []service.FieldError
+	if req.Language == "" {
+		fields = append(fields, service.FieldError{Field: "language", Reason: "is required"})
+	}
+	if req.Source == "" {
+		fields = append(fields, service.FieldError{Field: "source", Reason: "is required"})
+	} else if len(req.Source) > maxSourceBytes {
+		fields = append(fields, service.FieldError{Field: "source", Reason: "must be at most 64 KiB"})
+	}
+	if len(fields) > 0 {
+		return &service.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// SubmitSolution godoc
+// @Summary Отправить решение
+// @Description Ставит решение в очередь на проверку. Статус и вердикт доступны через GET /submissions/{id}.
+// @Description Первое принятое решение засчитывает задание пользователю.
+// @Tags submissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задания"
+// @Param submission body SubmitSolutionRequest true "Решение"
+// @Success 202 {object} models.Submission
+// @Failure 400 {object} Problem "Invalid ID or request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "user_id of another user"
+// @Failure 404 {object} Problem "Mission or user not found"
+// @Failure 413 {object} Problem "Request body too large"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to submit solution"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/{id}/submissions [post]
+func (h *Handler) SubmitSolution(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var req SubmitSolutionRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+	if req.UserID, ok = actingUser(w, r, req.UserID); !ok {
+		return
+	}
+
+	sub, err := h.Service.SubmitSolution(r.Context(), models.Submission{
+		UserID:    req.UserID,
+		MissionID: id,
+		Language:  req.Language,
+		Source:    req.Source,
+	})
+	if err != nil {
+		writeError(w, r, err, "Failed to submit solution")
+		return
+	}
+
+	w.Header().Set("Location", "/submissions/"+strconv.Itoa(sub.ID))
+	writeJSON(w, http.StatusAccepted, sub)
+}
+
+// GetSubmission godoc
+// @Summary Получить решение
+// @Description Возвращает статус проверки решения и, когда проверка завершена, вердикт. Доступно автору решения и администраторам.
+// @Tags submissions
+// @Produce json
+// @Param id path int true "ID решения"
+// @Success 200 {object} models.Submission
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Submission of another user"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 500 {object} Problem "Failed to get submission"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /submissions/{id} [get]
+func (h *Handler) GetSubmission(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	sub, err := h.Service.GetSubmission(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Failed to get submission")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sub)
+}
+
+// GetTestCases godoc
+// @Summary Получить тесты задания
+// @Description Возвращает тесты (stdin и ожидаемый stdout), на которых проверяются решения. Доступно автору задания и администраторам.
+// @Tags submissions
+// @Produce json
+// @Param id path int true "ID задания"
+// @Success 200 {array} models.TestCase
+// @Failure 400 {object} Problem "Invalid ID"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an admin or the mission's author"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 500 {object} Problem "Failed to list test cases"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/{id}/tests [get]
+func (h *Handler) GetTestCases(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	cases, err := h.Service.ListTestCases(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, "Failed to list test cases")
+		return
+	}
+	if cases == nil {
+		cases = []models.TestCase{}
+	}
+
+	writeJSON(w, http.StatusOK, cases)
+}
+
+// ReplaceTestCases godoc
+// @Summary Заменить тесты задания
+// @Description Заменяет все тесты задания переданным списком. Доступно автору задания и администраторам.
+// @Tags submissions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID задания"
+// @Param tests body []models.TestCase true "Тесты"
+// @Success 200 {array} models.TestCase
+// @Failure 400 {object} Problem "Invalid ID or request"
+// @Failure 401 {object} Problem "Authentication required"
+// @Failure 403 {object} Problem "Not an admin or the mission's author"
+// @Failure 404 {object} Problem "Not found"
+// @Failure 422 {object} Problem "Validation failed"
+// @Failure 500 {object} Problem "Failed to replace test cases"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /missions/{id}/tests [put]
+func (h *Handler) ReplaceTestCases(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	var cases []models.TestCase
+	if err := decodeJSON(w, r, &cases); err != nil {
+		writeError(w, r, err, "Invalid request")
+		return
+	}
+
+	stored, err := h.Service.ReplaceTestCases(r.Context(), id, cases)
+	if err != nil {
+		writeError(w, r, err, "Failed to replace test cases")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, stored)
+}
@@ -0,0 +1,22 @@
+CREATE TABLE test_cases (
+    id SERIAL PRIMARY KEY,
+    mission_id INTEGER NOT NULL REFERENCES missions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    input TEXT NOT NULL,
+    expected_output TEXT NOT NULL
+);
+
+CREATE INDEX test_cases_mission_id_idx ON test_cases (mission_id, position);
+
+CREATE TABLE submissions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mission_id INTEGER NOT NULL REFERENCES missions(id) ON DELETE CASCADE,
+    language TEXT NOT NULL,
+    source TEXT NOT NULL,
+    verdict TEXT NOT NULL,
+    detail TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX submissions_user_mission_idx ON submissions (user_id, mission_id);
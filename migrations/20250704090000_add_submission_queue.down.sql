@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS submissions_pending_idx;
+
+ALTER TABLE submissions ALTER COLUMN verdict DROP DEFAULT;
+
+ALTER TABLE submissions
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS lease_token,
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS status;
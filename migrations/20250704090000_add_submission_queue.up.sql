@@ -0,0 +1,12 @@
+ALTER TABLE submissions
+    ADD COLUMN status TEXT NOT NULL DEFAULT 'done',
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN last_error TEXT NOT NULL DEFAULT '',
+    ADD COLUMN locked_until TIMESTAMPTZ,
+    ADD COLUMN lease_token TEXT,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE submissions ALTER COLUMN status SET DEFAULT 'queued';
+ALTER TABLE submissions ALTER COLUMN verdict SET DEFAULT '';
+
+CREATE INDEX submissions_pending_idx ON submissions (id) WHERE status IN ('queued', 'running');
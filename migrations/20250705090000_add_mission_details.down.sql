@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS missions_category_idx;
+
+ALTER TABLE missions
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS archived,
+    DROP COLUMN IF EXISTS category,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS difficulty,
+    DROP COLUMN IF EXISTS description;
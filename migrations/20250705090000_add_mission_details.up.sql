@@ -0,0 +1,10 @@
+ALTER TABLE missions
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN difficulty TEXT NOT NULL DEFAULT 'easy' CHECK (difficulty IN ('easy', 'medium', 'hard')),
+    ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN category TEXT NOT NULL DEFAULT '',
+    ADD COLUMN archived BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE INDEX missions_category_idx ON missions (category);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS missions_search_vector_idx;
+
+ALTER TABLE missions DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,7 @@
+ALTER TABLE missions
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX missions_search_vector_idx ON missions USING GIN (search_vector);
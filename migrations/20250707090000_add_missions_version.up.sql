@@ -0,0 +1 @@
+ALTER TABLE missions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
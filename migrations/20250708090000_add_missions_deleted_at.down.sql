@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS missions_deleted_at_idx;
+ALTER TABLE missions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE missions ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX missions_deleted_at_idx ON missions (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS missions_author_id_idx;
+ALTER TABLE missions DROP COLUMN IF EXISTS author_id;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
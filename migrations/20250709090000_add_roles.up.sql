@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'player'
+    CHECK (role IN ('player', 'author', 'admin'));
+
+ALTER TABLE missions ADD COLUMN author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX missions_author_id_idx ON missions (author_id);
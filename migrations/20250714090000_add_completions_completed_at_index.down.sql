@@ -0,0 +1 @@
+DROP INDEX IF EXISTS completions_completed_at_idx;
@@ -0,0 +1 @@
+CREATE INDEX completions_completed_at_idx ON completions (completed_at);
@@ -0,0 +1,9 @@
+CREATE TABLE streaks (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    current_streak INTEGER NOT NULL DEFAULT 0,
+    longest_streak INTEGER NOT NULL DEFAULT 0,
+    freezes INTEGER NOT NULL DEFAULT 0,
+    freezes_earned INTEGER NOT NULL DEFAULT 0,
+    last_active DATE,
+    timezone TEXT NOT NULL DEFAULT 'UTC'
+);
@@ -0,0 +1,22 @@
+CREATE TABLE seasons (
+    id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL UNIQUE,
+    ends_at TIMESTAMPTZ NOT NULL,
+    archived_at TIMESTAMPTZ,
+    CHECK (ends_at > starts_at)
+);
+
+-- Final standings are a frozen copy: user_id has no foreign key, so that
+-- deleting a user leaves past seasons as they ended.
+CREATE TABLE season_standings (
+    season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    rank INTEGER NOT NULL,
+    username TEXT NOT NULL,
+    points INTEGER NOT NULL,
+    completed INTEGER NOT NULL,
+    PRIMARY KEY (season_id, user_id)
+);
+
+CREATE INDEX season_standings_rank_idx ON season_standings (season_id, rank, user_id);
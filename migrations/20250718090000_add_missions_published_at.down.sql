@@ -0,0 +1 @@
+ALTER TABLE missions DROP COLUMN IF EXISTS published_at;
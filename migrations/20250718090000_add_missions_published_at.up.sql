@@ -0,0 +1,2 @@
+ALTER TABLE missions ADD COLUMN published_at TIMESTAMPTZ NOT NULL DEFAULT now();
+UPDATE missions SET published_at = created_at;
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Scope limits what an API key may do on behalf of its owner.
+type Scope string
+
+const (
+	ScopeMissionsRead     Scope = "missions:read"
+	ScopeMissionsWrite    Scope = "missions:write"
+	ScopeSubmissionsWrite Scope = "submissions:write"
+)
+
+// Valid reports whether s is one of the known scopes.
+func (s Scope) Valid() bool {
+	switch s {
+	case ScopeMissionsRead, ScopeMissionsWrite, ScopeSubmissionsWrite:
+		return true
+	}
+	return false
+}
+
+// APIKey lets a non-interactive client act as its owner, restricted to
+// Scopes. Only the SHA-256 of the key is stored; Prefix identifies the key
+// in listings.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	OwnerID    int        `json:"owner_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
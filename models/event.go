@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// EventType names something that happened to a user and may award badges.
+type EventType string
+
+const (
+	EventMissionCompleted   EventType = "mission_completed"
+	EventSubmissionAccepted EventType = "submission_accepted"
+	EventStreakExtended     EventType = "streak_extended"
+)
+
+// Valid reports whether t is one of the known event types.
+func (t EventType) Valid() bool {
+	switch t {
+	case EventMissionCompleted, EventSubmissionAccepted, EventStreakExtended:
+		return true
+	}
+	return false
+}
+
+// Event is a domain event of a user.
+type Event struct {
+	Type   EventType
+	UserID int
+	// MissionID is set for mission and submission events.
+	MissionID int
+	// Streak is the new streak length of EventStreakExtended, in days.
+	Streak int
+	At     time.Time
+}
+
+// Achievement is a badge awarded to a user. It keeps the badge's name at
+// the time of the award, so it stays on the profile when rules change.
+type Achievement struct {
+	UserID    int       `json:"-"`
+	BadgeID   string    `json:"badge_id"`
+	Name      string    `json:"name"`
+	AwardedAt time.Time `json:"awarded_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// GamificationRules decide the level and badges shown on user profiles.
+type GamificationRules struct {
+	// Levels are ordered by MinPoints; a user has the last level reached.
+	Levels  []Level     `json:"levels"`
+	Badges  []Badge     `json:"badges"`
+	Streaks StreakRules `json:"streaks"`
+}
+
+type Level struct {
+	Name      string `json:"name"`
+	MinPoints int    `json:"min_points"`
+}
+
+// Badge is awarded when all of its non-zero conditions hold. Awarded
+// badges are kept even if the rules or missions change later.
+type Badge struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Event restricts the badge to one kind of event: it is only checked
+	// when such an event happens.
+	Event EventType `json:"event,omitempty" enums:"mission_completed,submission_accepted,streak_extended"`
+	// MinPoints, MinCompleted and AllInCategory count only missions
+	// matching Category and Difficulty when those are set.
+	MinPoints    int        `json:"min_points,omitempty"`
+	MinCompleted int        `json:"min_completed,omitempty"`
+	Category     string     `json:"category,omitempty"`
+	Difficulty   Difficulty `json:"difficulty,omitempty" enums:"easy,medium,hard"`
+	// AllInCategory requires every current mission of Category.
+	AllInCategory bool `json:"all_in_category,omitempty"`
+	// SolvedWithin requires a mission completed at most this long after it
+	// was published; it is checked on mission_completed events.
+	SolvedWithin Duration `json:"solved_within,omitempty" swaggertype:"string" example:"1h"`
+	// MinStreak requires a streak of at least this many days; it is
+	// checked on streak_extended events.
+	MinStreak int `json:"min_streak,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is a stored response replayed for retried requests
+// carrying the same Idempotency-Key.
+type IdempotencyRecord struct {
+	Key    string
+	UserID int
+	Path   string
+	// StatusCode is 0 while the key is reserved but the response has not
+	// been stored yet.
+	StatusCode int
+	Body       []byte
+	CreatedAt  time.Time
+}
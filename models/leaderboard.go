@@ -0,0 +1,19 @@
+package models
+
+// LeaderboardEntry is the standing of one user. Users with equal points
+// share a rank.
+type LeaderboardEntry struct {
+	// Rank is 0 for users without points on the leaderboard.
+	Rank      int    `json:"rank"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Points    int    `json:"points"`
+	Completed int    `json:"completed"`
+}
+
+// LeaderboardPage is one page of a leaderboard, best users first.
+type LeaderboardPage struct {
+	Items []LeaderboardEntry `json:"items"`
+	// NextCursor is passed as ?cursor= to fetch the next page; it is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
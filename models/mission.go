@@ -1,7 +1,41 @@
 package models
 
+import "time"
+
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
+)
+
 type Mission struct {
-	ID     int    `json:"id"`
-	Title  string `json:"title"`
-	Points int    `json:"points"`
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	// Description is markdown shown to players.
+	Description string     `json:"description"`
+	Difficulty  Difficulty `json:"difficulty" enums:"easy,medium,hard"`
+	Tags        []string   `json:"tags"`
+	Category    string     `json:"category"`
+	Points      int        `json:"points"`
+	Archived    bool       `json:"archived"`
+	// AuthorID is the user who created the mission; it is set by the server.
+	AuthorID int `json:"author_id,omitempty"`
+	// Version is incremented on every update and exposed as the ETag.
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// PublishedAt is when players last got access to the mission: its
+	// creation, its restore from the trash or its update out of the archive.
+	PublishedAt time.Time `json:"published_at"`
+	// DeletedAt is set while the mission is in the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// MissionPage is one page of a mission listing.
+type MissionPage struct {
+	Items []Mission `json:"items"`
+	// NextCursor is passed as ?cursor= to fetch the next page; it is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
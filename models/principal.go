@@ -0,0 +1,30 @@
+package models
+
+import "slices"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID   int
+	Username string
+	Role     Role
+	// APIKeyID is set when the caller authenticated with an API key of
+	// the user; Scopes then limits what the request may do.
+	APIKeyID int
+	Scopes   []Scope
+}
+
+// HasRole reports whether the principal has one of roles.
+func (p Principal) HasRole(roles ...Role) bool {
+	for _, r := range roles {
+		if p.Role == r {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the principal may act within scope. Users
+// authenticated with a token are not limited by scopes.
+func (p Principal) HasScope(scope Scope) bool {
+	return p.APIKeyID == 0 || slices.Contains(p.Scopes, scope)
+}
@@ -1,7 +1,20 @@
 package models
 
 type Profile struct {
+	// TotalPoints are the lifetime points; SeasonPoints only count the
+	// current season.
 	TotalPoints  int      `json:"total_points"`
 	Level        string   `json:"level"`
 	Achievements []string `json:"achievements"`
+	// Badges are the awarded achievements with their award times. It is
+	// only filled when achievements are persisted.
+	Badges []Achievement `json:"badges,omitempty"`
+	// CurrentStreak is 0 once more days were missed than the freezes
+	// cover.
+	CurrentStreak int `json:"current_streak"`
+	LongestStreak int `json:"longest_streak"`
+	StreakFreezes int `json:"streak_freezes"`
+	// SeasonID is the current season, 0 when there is none.
+	SeasonID     int `json:"season_id,omitempty"`
+	SeasonPoints int `json:"season_points"`
 }
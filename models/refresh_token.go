@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// RefreshToken is a single-use token exchanged for a new access token.
+// Every refresh replaces it with a successor in the same family; presenting
+// a used token again revokes the whole family.
+type RefreshToken struct {
+	ID     int
+	UserID int
+	// TokenHash is the SHA-256 of the token; the token itself is never stored.
+	TokenHash string
+	FamilyID  string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
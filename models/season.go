@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Season is a quarter of the year with its own leaderboard: completions
+// from StartsAt until EndsAt count towards it.
+type Season struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name" example:"2025 Q3"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	// ArchivedAt is set once the season has ended and its final standings
+	// have been stored.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// Contains reports whether t falls within the season.
+func (s Season) Contains(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
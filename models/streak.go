@@ -0,0 +1,29 @@
+package models
+
+// Streak counts the consecutive days on which a user completed missions or
+// had submissions accepted. Days begin at midnight in the user's Timezone.
+type Streak struct {
+	UserID  int `json:"-"`
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+	// Freezes cover missed days: one is used up automatically for every
+	// missed day, so that the streak goes on with the next activity.
+	Freezes int `json:"freezes"`
+	// FreezesEarned counts every freeze ever earned through points, so that
+	// the same points never earn a freeze twice.
+	FreezesEarned int `json:"-"`
+	// LastActive is the last day with activity as YYYY-MM-DD, empty before
+	// the first one.
+	LastActive string `json:"last_active,omitempty" example:"2025-07-14"`
+	// Timezone is an IANA time zone name.
+	Timezone string `json:"timezone" example:"Europe/Berlin"`
+}
+
+// StreakRules decide how streak freezes are earned.
+type StreakRules struct {
+	// FreezePoints earns a freeze for every so many points; zero disables
+	// freezes.
+	FreezePoints int `json:"freeze_points,omitempty"`
+	// MaxFreezes is the number of freezes a user can hold at once.
+	MaxFreezes int `json:"max_freezes,omitempty"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type TestCase struct {
+	ID             int    `json:"id"`
+	MissionID      int    `json:"mission_id"`
+	Input          string `json:"input"`
+	ExpectedOutput string `json:"expected_output"`
+}
+
+type Verdict string
+
+const (
+	VerdictAccepted          Verdict = "accepted"
+	VerdictWrongAnswer       Verdict = "wrong_answer"
+	VerdictTimeLimitExceeded Verdict = "time_limit_exceeded"
+	VerdictRuntimeError      Verdict = "runtime_error"
+	VerdictCompilationError  Verdict = "compilation_error"
+)
+
+// SubmissionStatus is the position of a submission in the judging queue.
+type SubmissionStatus string
+
+const (
+	SubmissionQueued  SubmissionStatus = "queued"
+	SubmissionRunning SubmissionStatus = "running"
+	SubmissionDone    SubmissionStatus = "done"
+	// SubmissionDead marks a job that kept failing and will not be retried.
+	SubmissionDead SubmissionStatus = "dead"
+)
+
+type Submission struct {
+	ID          int              `json:"id"`
+	UserID      int              `json:"user_id"`
+	MissionID   int              `json:"mission_id"`
+	Language    string           `json:"language"`
+	Source      string           `json:"source"`
+	Status      SubmissionStatus `json:"status"`
+	Verdict     Verdict          `json:"verdict,omitempty"`
+	Detail      string           `json:"detail,omitempty"`
+	Attempts    int              `json:"attempts"`
+	LastError   string           `json:"last_error,omitempty"`
+	LockedUntil time.Time        `json:"-"`
+	// LeaseToken identifies the claim that holds the lease; it changes with every claim.
+	LeaseToken string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Role decides what a user may do: players read missions and submit
+// solutions, authors also manage their own missions, admins manage all.
+type Role string
+
+const (
+	RolePlayer Role = "player"
+	RoleAuthor Role = "author"
+	RoleAdmin  Role = "admin"
+)
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RolePlayer, RoleAuthor, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+type User struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	Role      Role      `json:"role" enums:"player,author,admin"`
+	CreatedAt time.Time `json:"created_at"`
+	// PasswordHash is the bcrypt hash of a local account's password; it is
+	// empty for users that cannot log in with a password.
+	PasswordHash string `json:"-"`
+}
+
+type Completion struct {
+	UserID      int       `json:"user_id"`
+	MissionID   int       `json:"mission_id"`
+	CompletedAt time.Time `json:"completed_at"`
+}
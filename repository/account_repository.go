@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+const refreshTokenColumns = `id, user_id, token_hash, family_id, expires_at, created_at, used_at, revoked_at`
+
+func scanRefreshToken(row interface{ Scan(...any) error }) (models.RefreshToken, error) {
+	var t models.RefreshToken
+	err := row.Scan(&t.ID, &t.UserID, &t.TokenHash, &t.FamilyID, &t.ExpiresAt, &t.CreatedAt, &t.UsedAt, &t.RevokedAt)
+	return t, mapError(err)
+}
+
+func (r *PostgresRepository) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	var u models.User
+	err := r.DB.QueryRowContext(ctx, "SELECT id, username, role, created_at, password_hash FROM users WHERE username = $1", username).
+		Scan(&u.ID, &u.Username, &u.Role, &u.CreatedAt, &u.PasswordHash)
+	return u, mapError(err)
+}
+
+func (r *PostgresRepository) AddRefreshToken(ctx context.Context, t models.RefreshToken) (models.RefreshToken, error) {
+	return scanRefreshToken(r.DB.QueryRowContext(ctx, `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+refreshTokenColumns,
+		t.UserID, t.TokenHash, t.FamilyID, t.ExpiresAt,
+	))
+}
+
+func (r *PostgresRepository) GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	return scanRefreshToken(r.DB.QueryRowContext(ctx, "SELECT "+refreshTokenColumns+" FROM refresh_tokens WHERE token_hash = $1", tokenHash))
+}
+
+// UseRefreshToken sets used_at only if it is still empty, so concurrent
+// refreshes with the same token cannot both succeed.
+func (r *PostgresRepository) UseRefreshToken(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "UPDATE refresh_tokens SET used_at = now() WHERE id = $1 AND used_at IS NULL", id)
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		var exists int
+		if err := r.DB.QueryRowContext(ctx, "SELECT 1 FROM refresh_tokens WHERE id = $1", id).Scan(&exists); err != nil {
+			return mapError(err)
+		}
+		return service.ErrConflict
+	}
+	return nil
+}
+
+func (r *PostgresRepository) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL", familyID)
+	return mapError(err)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+func (r *PostgresRepository) AwardAchievement(ctx context.Context, a models.Achievement) (bool, error) {
+	res, err := r.DB.ExecContext(ctx, `
+		INSERT INTO achievements (user_id, badge_id, name, awarded_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, badge_id) DO NOTHING`,
+		a.UserID, a.BadgeID, a.Name, a.AwardedAt,
+	)
+	if err != nil {
+		return false, mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *PostgresRepository) ListAchievements(ctx context.Context, userID int) ([]models.Achievement, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT user_id, badge_id, name, awarded_at
+		FROM achievements
+		WHERE user_id = $1
+		ORDER BY awarded_at, badge_id`, userID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	achievements := []models.Achievement{}
+	for rows.Next() {
+		var a models.Achievement
+		if err := rows.Scan(&a.UserID, &a.BadgeID, &a.Name, &a.AwardedAt); err != nil {
+			return nil, mapError(err)
+		}
+		achievements = append(achievements, a)
+	}
+	return achievements, rows.Err()
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, owner_id, created_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (models.APIKey, error) {
+	var k models.APIKey
+	var scopes []string
+	err := row.Scan(&k.ID, &k.Name, &k.Prefix, &k.KeyHash, pq.Array(&scopes), &k.OwnerID, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	if err != nil {
+		return models.APIKey{}, mapError(err)
+	}
+	k.Scopes = make([]models.Scope, len(scopes))
+	for i, s := range scopes {
+		k.Scopes[i] = models.Scope(s)
+	}
+	return k, nil
+}
+
+func (r *PostgresRepository) AddAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, error) {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+	return scanAPIKey(r.DB.QueryRowContext(ctx, `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, owner_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+apiKeyColumns,
+		k.Name, k.Prefix, k.KeyHash, pq.Array(scopes), k.OwnerID,
+	))
+}
+
+func (r *PostgresRepository) GetAPIKey(ctx context.Context, id int) (models.APIKey, error) {
+	return scanAPIKey(r.DB.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = $1", id))
+}
+
+func (r *PostgresRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	return scanAPIKey(r.DB.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1", keyHash))
+}
+
+func (r *PostgresRepository) ListAPIKeys(ctx context.Context, ownerID int) ([]models.APIKey, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE $1 = 0 OR owner_id = $1 ORDER BY id", ownerID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *PostgresRepository) RevokeAPIKey(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, now()) WHERE id = $1", id)
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	_, err := r.DB.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $2 WHERE id = $1", id, usedAt)
+	return mapError(err)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/lib/pq"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// Errors for constraint violations without a more specific meaning. Their
+// messages reach API clients, so they never include the driver's text.
+var (
+	errDuplicate        = fmt.Errorf("record already exists: %w", service.ErrConflict)
+	errMissingReference = fmt.Errorf("referenced record does not exist: %w", service.ErrValidation)
+	errCheckViolation   = fmt.Errorf("value out of range: %w", service.ErrValidation)
+)
+
+// constraintErrors gives violations of single constraints a more specific
+// meaning than their error code.
+var constraintErrors = map[string]error{
+	"completions_pkey":      service.ErrAlreadyCompleted,
+	"idempotency_keys_pkey": service.ErrIdempotencyKeyTaken,
+}
+
+// mapError translates driver errors into the domain errors of the service
+// package so that callers never have to know about database/sql or pq.
+// The driver error of a constraint violation is logged, not wrapped.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if mapped, ok := constraintErrors[pqErr.Constraint]; ok {
+			return mapped
+		}
+		var mapped error
+		switch pqErr.Code {
+		case "23505": // unique_violation
+			mapped = errDuplicate
+		case "23503": // foreign_key_violation
+			mapped = errMissingReference
+		case "23514": // check_violation
+			mapped = errCheckViolation
+		default:
+			return err
+		}
+		slog.Info("constraint violation", "code", string(pqErr.Code), "constraint", pqErr.Constraint, "error", err)
+		return mapped
+	}
+	return err
+}
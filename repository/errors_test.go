@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestMapErrorHidesDriverDetails(t *testing.T) {
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"23505", service.ErrConflict},
+		{"23503", service.ErrValidation},
+		{"23514", service.ErrValidation},
+	}
+	for _, tt := range tests {
+		err := mapError(&pq.Error{Code: pq.ErrorCode(tt.code), Message: "violates constraint on table secret_internal"})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.code, tt.want, err)
+		}
+		if strings.Contains(err.Error(), "secret_internal") {
+			t.Errorf("%s: driver message leaked: %q", tt.code, err)
+		}
+	}
+
+	if err := mapError(&pq.Error{Code: "23505", Constraint: "completions_pkey"}); !errors.Is(err, service.ErrAlreadyCompleted) {
+		t.Errorf("expected ErrAlreadyCompleted, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+func (r *PostgresRepository) GetIdempotencyRecord(ctx context.Context, userID int, key string) (models.IdempotencyRecord, error) {
+	var rec models.IdempotencyRecord
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT user_id, key, path, status_code, body, created_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2`, userID, key).
+		Scan(&rec.UserID, &rec.Key, &rec.Path, &rec.StatusCode, &rec.Body, &rec.CreatedAt)
+	return rec, mapError(err)
+}
+
+// AddCompletionWithKey reserves the key before recording the completion.
+// A concurrent request with the same key blocks on the primary key until
+// this transaction ends and then fails with service.ErrIdempotencyKeyTaken.
+func (r *PostgresRepository) AddCompletionWithKey(ctx context.Context, rec models.IdempotencyRecord, missionID int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (user_id, key, path, status_code, body)
+		VALUES ($1, $2, $3, 0, '')`,
+		rec.UserID, rec.Key, rec.Path,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	if err := addCompletion(ctx, tx, rec.UserID, missionID); err != nil {
+		return err
+	}
+	return mapError(tx.Commit())
+}
+
+func (r *PostgresRepository) SaveIdempotencyRecord(ctx context.Context, rec models.IdempotencyRecord) error {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $3, body = $4
+		WHERE user_id = $1 AND key = $2 AND status_code = 0`,
+		rec.UserID, rec.Key, rec.StatusCode, rec.Body,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return mapError(err)
+	}
+	_, err = r.GetIdempotencyRecord(ctx, rec.UserID, rec.Key)
+	return err
+}
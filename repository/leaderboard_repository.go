@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// scoredCompletions selects the completions since $1 and before $3
+// (unbounded when NULL) of missions in category $2 (all when empty).
+const scoredCompletions = `
+	FROM completions c
+	JOIN missions m ON m.id = c.mission_id
+	WHERE ($1::timestamptz IS NULL OR c.completed_at >= $1)
+		AND ($3::timestamptz IS NULL OR c.completed_at < $3)
+		AND ($2 = '' OR m.category = $2)`
+
+// rankedScores ranks users by the points of their scoredCompletions. RANK
+// gives users with equal points the same rank.
+const rankedScores = `
+	WITH scores AS (
+		SELECT c.user_id, SUM(m.points) AS points, COUNT(*) AS completed` + scoredCompletions + `
+		GROUP BY c.user_id
+	)
+	SELECT RANK() OVER (ORDER BY s.points DESC) AS rank, s.user_id, u.username, s.points, s.completed
+	FROM scores s
+	JOIN users u ON u.id = s.user_id`
+
+func leaderboardArgs(q service.LeaderboardQuery) []any {
+	since := sql.NullTime{Time: q.Since, Valid: !q.Since.IsZero()}
+	until := sql.NullTime{Time: q.Until, Valid: !q.Until.IsZero()}
+	return []any{since, q.Category, until}
+}
+
+func scanLeaderboardEntry(row interface{ Scan(...any) error }) (models.LeaderboardEntry, error) {
+	var e models.LeaderboardEntry
+	err := row.Scan(&e.Rank, &e.UserID, &e.Username, &e.Points, &e.Completed)
+	return e, mapError(err)
+}
+
+// cursorArgs returns the points and user ID of after, NULL when it is nil.
+func cursorArgs(after *service.LeaderboardCursor) (sql.NullInt64, sql.NullInt64) {
+	if after == nil {
+		return sql.NullInt64{}, sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(after.Points), Valid: true}, sql.NullInt64{Int64: int64(after.UserID), Valid: true}
+}
+
+func (r *PostgresRepository) Leaderboard(ctx context.Context, q service.LeaderboardQuery) ([]models.LeaderboardEntry, error) {
+	points, userID := cursorArgs(q.After)
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT * FROM (`+rankedScores+`) ranked
+		WHERE ($4::int IS NULL OR points < $4 OR (points = $4 AND user_id > $5))
+		ORDER BY rank, user_id
+		LIMIT $6`,
+		append(leaderboardArgs(q), points, userID, q.Limit)...,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return scanLeaderboard(rows)
+}
+
+// scanLeaderboard reads every entry of rows and closes them.
+func scanLeaderboard(rows *sql.Rows) ([]models.LeaderboardEntry, error) {
+	defer rows.Close()
+	entries := []models.LeaderboardEntry{}
+	for rows.Next() {
+		e, err := scanLeaderboardEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *PostgresRepository) UserRank(ctx context.Context, userID int, q service.LeaderboardQuery) (models.LeaderboardEntry, error) {
+	return scanLeaderboardEntry(r.DB.QueryRowContext(ctx,
+		"SELECT * FROM ("+rankedScores+") ranked WHERE user_id = $4",
+		append(leaderboardArgs(q), userID)...,
+	))
+}
+
+func (r *PostgresRepository) UserPoints(ctx context.Context, userID int, q service.LeaderboardQuery) (int, error) {
+	var points int
+	err := r.DB.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(m.points), 0)"+scoredCompletions+" AND c.user_id = $4",
+		append(leaderboardArgs(q), userID)...,
+	).Scan(&points)
+	return points, mapError(err)
+}
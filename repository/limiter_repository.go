@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pseudoerr/mission-service/service"
+)
+
+var _ service.LimiterStore = (*PostgresRepository)(nil)
+
+// refilledTokens is the token count of an existing bucket refilled up to
+// its capacity $2 at the rate of $3 tokens per second.
+const refilledTokens = `LEAST($2::float8, b.tokens + GREATEST(0, EXTRACT(EPOCH FROM now() - b.updated_at)::float8) * $3::float8)`
+
+// Take refills and takes from the bucket in a single upsert, so concurrent
+// requests on any instance see each other's tokens. The database clock is
+// used for every instance. Like every LimiterStore it fails open: while the
+// database is unreachable requests are not limited.
+func (r *PostgresRepository) Take(ctx context.Context, key string, limit service.RateLimit) (service.LimitDecision, error) {
+	var tokens float64
+	var allowed bool
+	err := r.DB.QueryRowContext(ctx, `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - 1, true, now())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE WHEN `+refilledTokens+` >= 1 THEN `+refilledTokens+` - 1 ELSE `+refilledTokens+` END,
+			allowed = `+refilledTokens+` >= 1,
+			updated_at = now()
+		RETURNING tokens, allowed`,
+		key, float64(limit.Requests), limit.Rate(),
+	).Scan(&tokens, &allowed)
+	if err != nil {
+		return service.LimitDecision{}, mapError(err)
+	}
+	return service.NewLimitDecision(limit, tokens, allowed), nil
+}
+
+// EvictIdle compares updated_at with the database clock, which Take sets
+// it from, rather than the clock of this instance.
+func (r *PostgresRepository) EvictIdle(ctx context.Context, idle time.Duration) error {
+	_, err := r.DB.ExecContext(ctx,
+		"DELETE FROM rate_limit_buckets WHERE updated_at < now() - make_interval(secs => $1)",
+		idle.Seconds(),
+	)
+	return mapError(err)
+}
@@ -3,7 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
 	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
 )
 
 type PostgresRepository struct {
@@ -14,51 +20,232 @@ func NewPostgresRepository(db *sql.DB) *PostgresRepository {
 	return &PostgresRepository{DB: db}
 }
 
-func (r *PostgresRepository) ListMissions(ctx context.Context) ([]models.Mission, error) {
-	rows, err := r.DB.QueryContext(ctx, "SELECT id, title, points FROM missions")
-	if err != nil {
-		return nil, err
-	}
+const missionColumns = `id, title, description, difficulty, tags, category, points, archived, author_id, version, created_at, updated_at, published_at, deleted_at`
+
+func scanMission(row interface{ Scan(...any) error }) (models.Mission, error) {
+	var (
+		m        models.Mission
+		authorID sql.NullInt64
+	)
+	err := row.Scan(
+		&m.ID, &m.Title, &m.Description, &m.Difficulty, pq.Array(&m.Tags), &m.Category, &m.Points, &m.Archived,
+		&authorID, &m.Version, &m.CreatedAt, &m.UpdatedAt, &m.PublishedAt, &m.DeletedAt,
+	)
+	m.AuthorID = int(authorID.Int64)
+	return m, mapError(err)
+}
+
+func scanMissions(rows *sql.Rows) ([]models.Mission, error) {
 	defer rows.Close()
 
 	var missions []models.Mission
 	for rows.Next() {
-		var m models.Mission
-		if err := rows.Scan(&m.ID, &m.Title, &m.Points); err != nil {
-			return nil, err
+		m, err := scanMission(rows)
+		if err != nil {
+			return nil, mapError(err)
 		}
 		missions = append(missions, m)
 	}
-	return missions, nil
+	return missions, rows.Err()
 }
 
-func (r *PostgresRepository) AddMission(ctx context.Context, m models.Mission) (models.Mission, error) {
-	err := r.DB.QueryRowContext(
-		ctx,
-		"INSERT INTO missions (title, points) VALUES ($1, $2) RETURNING id",
-		m.Title, m.Points,
-	).Scan(&m.ID)
+// ListMissions uses keyset pagination: the cursor carries the sort value and
+// ID of the last returned row, so pages stay stable under concurrent inserts.
+func (r *PostgresRepository) ListMissions(ctx context.Context, opts service.ListOptions) (models.MissionPage, error) {
+	field, desc, err := service.SortField(opts.Sort)
+	if err != nil {
+		return models.MissionPage{}, mapError(err)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = service.DefaultListLimit
+	}
+
+	var (
+		where = []string{"deleted_at IS NULL"}
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if opts.Deleted {
+		where[0] = "deleted_at IS NOT NULL"
+	}
+
+	if opts.Difficulty != "" {
+		where = append(where, "difficulty = "+arg(opts.Difficulty))
+	}
+	if opts.Tag != "" {
+		where = append(where, arg(opts.Tag)+" = ANY(tags)")
+	}
+	if opts.Category != "" {
+		where = append(where, "category = "+arg(opts.Category))
+	}
+	if opts.MinPoints != nil {
+		where = append(where, "points >= "+arg(*opts.MinPoints))
+	}
+	if opts.MaxPoints != nil {
+		where = append(where, "points <= "+arg(*opts.MaxPoints))
+	}
+
+	cmp, dir := ">", "ASC"
+	if desc {
+		cmp, dir = "<", "DESC"
+	}
+	orderBy := "id " + dir
+
+	if opts.Cursor != "" {
+		cur, err := service.DecodeCursor(opts.Sort, opts.Cursor)
+		if err != nil {
+			return models.MissionPage{}, mapError(err)
+		}
+		switch field {
+		case "":
+			where = append(where, "id "+cmp+" "+arg(cur.ID))
+		case service.SortPoints:
+			where = append(where, "(points, id) "+cmp+" ("+arg(cur.Points)+", "+arg(cur.ID)+")")
+		case service.SortCreatedAt:
+			where = append(where, "(created_at, id) "+cmp+" ("+arg(cur.CreatedAt)+", "+arg(cur.ID)+")")
+		}
+	}
+	if field != "" {
+		orderBy = field + " " + dir + ", " + orderBy
+	}
+
+	query := "SELECT " + missionColumns + " FROM missions WHERE " + strings.Join(where, " AND ")
+	query += " ORDER BY " + orderBy + " LIMIT " + arg(opts.Limit+1)
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.MissionPage{}, mapError(err)
+	}
+	missions, err := scanMissions(rows)
+	if err != nil {
+		return models.MissionPage{}, mapError(err)
+	}
 
-	return m, err
+	page := models.MissionPage{Items: missions}
+	if len(missions) > opts.Limit {
+		page.Items = missions[:opts.Limit]
+		page.NextCursor = service.EncodeCursor(opts.Sort, page.Items[opts.Limit-1])
+	}
+	if page.Items == nil {
+		page.Items = []models.Mission{}
+	}
+	return page, nil
+}
+
+// SearchMissions matches query against the weighted search_vector column
+// (title over description) and ranks the hits with ts_rank.
+func (r *PostgresRepository) SearchMissions(ctx context.Context, query string, limit int) ([]models.Mission, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT `+missionColumns+`
+		FROM missions, websearch_to_tsquery('simple', $1) AS q
+		WHERE search_vector @@ q AND deleted_at IS NULL
+		ORDER BY ts_rank(search_vector, q) DESC, id
+		LIMIT $2`, query, limit)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	missions, err := scanMissions(rows)
+	if missions == nil && err == nil {
+		missions = []models.Mission{}
+	}
+	return missions, mapError(err)
+}
+
+func (r *PostgresRepository) AddMission(ctx context.Context, m models.Mission) (models.Mission, error) {
+	service.NormalizeMission(&m)
+	return scanMission(r.DB.QueryRowContext(ctx, `
+		INSERT INTO missions (title, description, difficulty, tags, category, points, archived, author_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0))
+		RETURNING `+missionColumns,
+		m.Title, m.Description, m.Difficulty, pq.Array(m.Tags), m.Category, m.Points, m.Archived, m.AuthorID,
+	))
 }
 
 func (r *PostgresRepository) GetByID(ctx context.Context, id int) (models.Mission, error) {
-	var m models.Mission
-	err := r.DB.QueryRowContext(ctx, "SELECT id, title, points FROM missions WHERE id = $1", id).
-		Scan(&m.ID, &m.Title, &m.Points)
-	return m, err
+	return scanMission(r.DB.QueryRowContext(ctx, "SELECT "+missionColumns+" FROM missions WHERE id = $1 AND deleted_at IS NULL", id))
+}
+
+func (r *PostgresRepository) GetDeleted(ctx context.Context, id int) (models.Mission, error) {
+	return scanMission(r.DB.QueryRowContext(ctx, "SELECT "+missionColumns+" FROM missions WHERE id = $1 AND deleted_at IS NOT NULL", id))
 }
 
+// UpdateMission returns the stored row, so the caller sees server-managed
+// fields such as updated_at. The author is never changed, and un-archiving
+// the mission publishes it again. A non-zero m.Version must match the stored
+// version; the update increments it. It returns service.ErrNotFound for
+// unknown IDs and service.ErrVersionMismatch for stale versions.
 func (r *PostgresRepository) UpdateMission(ctx context.Context, m models.Mission) (models.Mission, error) {
-	_, err := r.DB.ExecContext(
-		ctx,
-		"UPDATE missions SET title = $1, points = $2 WHERE id = $3",
-		m.Title, m.Points, m.ID,
-	)
-	return m, err
+	service.NormalizeMission(&m)
+	updated, err := scanMission(r.DB.QueryRowContext(ctx, `
+		UPDATE missions
+		SET title = $1, description = $2, difficulty = $3, tags = $4, category = $5, points = $6, archived = $7,
+			version = version + 1, updated_at = now(),
+			published_at = CASE WHEN archived AND NOT $7 THEN now() ELSE published_at END
+		WHERE id = $8 AND deleted_at IS NULL AND ($9 = 0 OR version = $9)
+		RETURNING `+missionColumns,
+		m.Title, m.Description, m.Difficulty, pq.Array(m.Tags), m.Category, m.Points, m.Archived, m.ID, m.Version,
+	))
+	if errors.Is(err, service.ErrNotFound) {
+		return models.Mission{}, r.missingOrStale(ctx, m.ID)
+	}
+	return updated, err
+}
+
+// DeleteMission soft-deletes the mission if version is zero or matches the
+// stored version. The row stays in place so completion history survives.
+func (r *PostgresRepository) DeleteMission(ctx context.Context, id, version int) error {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE missions
+		SET deleted_at = now(), version = version + 1, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL AND ($2 = 0 OR version = $2)`, id, version)
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return r.missingOrStale(ctx, id)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) RestoreMission(ctx context.Context, id int) (models.Mission, error) {
+	return scanMission(r.DB.QueryRowContext(ctx, `
+		UPDATE missions
+		SET deleted_at = NULL, version = version + 1, updated_at = now(), published_at = now()
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING `+missionColumns, id))
 }
 
-func (r *PostgresRepository) DeleteMission(ctx context.Context, id int) error {
-	_, err := r.DB.Exec("DELETE FROM missions WHERE id = $1", id)
-	return err
+// PurgeMission deletes the row; completions, test cases and submissions go
+// with it through ON DELETE CASCADE.
+func (r *PostgresRepository) PurgeMission(ctx context.Context, id int) error {
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM missions WHERE id = $1", id)
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return service.ErrNotFound
+	}
+	return nil
+}
+
+// missingOrStale explains why a conditional write on the mission matched no row.
+func (r *PostgresRepository) missingOrStale(ctx context.Context, id int) error {
+	var exists int
+	err := r.DB.QueryRowContext(ctx, "SELECT 1 FROM missions WHERE id = $1 AND deleted_at IS NULL", id).Scan(&exists)
+	if err != nil {
+		return mapError(err)
+	}
+	return service.ErrVersionMismatch
 }
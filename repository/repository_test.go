@@ -0,0 +1,394 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/repository"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+// newTestRepository connects to the migrated database in TEST_DATABASE_URL
+// and skips the test when it is not set.
+func newTestRepository(t *testing.T) *repository.PostgresRepository {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("could not open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("could not ping db: %v", err)
+	}
+	return repository.NewPostgresRepository(db)
+}
+
+func TestUpdateMissionReturnsStoredRow(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	added, err := repo.AddMission(ctx, models.Mission{Title: "Repository test", Points: 10})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.PurgeMission(ctx, added.ID) })
+
+	updated, err := repo.UpdateMission(ctx, models.Mission{ID: added.ID, Title: "Renamed", Points: 20})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Title != "Renamed" || !updated.CreatedAt.Equal(added.CreatedAt) || updated.Version != added.Version+1 {
+		t.Errorf("expected stored row, got %+v", updated)
+	}
+
+	stale := models.Mission{ID: added.ID, Title: "Stale", Points: 30, Version: added.Version}
+	if _, err := repo.UpdateMission(ctx, stale); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch, got %v", err)
+	}
+	if err := repo.DeleteMission(ctx, added.ID, added.Version); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch on delete, got %v", err)
+	}
+}
+
+func TestUpdateAndDeleteUnknownMission(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	if _, err := repo.UpdateMission(ctx, models.Mission{ID: -1, Title: "Ghost", Points: 10}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on update, got %v", err)
+	}
+	if err := repo.DeleteMission(ctx, -1, 0); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on delete, got %v", err)
+	}
+}
+
+func TestSoftDeleteRestoreAndPurge(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	added, err := repo.AddMission(ctx, models.Mission{Title: "Trash me", Points: 10})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.PurgeMission(ctx, added.ID) })
+
+	if err := repo.DeleteMission(ctx, added.ID, added.Version); err != nil {
+		t.Fatalf("could not delete mission: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, added.ID); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected deleted mission to be hidden, got %v", err)
+	}
+	if err := repo.DeleteMission(ctx, added.ID, 0); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on second delete, got %v", err)
+	}
+
+	trash, err := repo.ListMissions(ctx, service.ListOptions{Deleted: true, Limit: service.MaxListLimit})
+	if err != nil {
+		t.Fatalf("could not list trash: %v", err)
+	}
+	found := false
+	for _, m := range trash.Items {
+		found = found || m.ID == added.ID && m.DeletedAt != nil
+	}
+	if !found {
+		t.Errorf("deleted mission %d not in trash", added.ID)
+	}
+
+	restored, err := repo.RestoreMission(ctx, added.ID)
+	if err != nil {
+		t.Fatalf("could not restore mission: %v", err)
+	}
+	if restored.DeletedAt != nil || restored.Version != added.Version+2 {
+		t.Errorf("unexpected restored mission %+v", restored)
+	}
+	if _, err := repo.RestoreMission(ctx, added.ID); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a live mission, got %v", err)
+	}
+
+	if err := repo.PurgeMission(ctx, added.ID); err != nil {
+		t.Fatalf("could not purge mission: %v", err)
+	}
+	if err := repo.PurgeMission(ctx, added.ID); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on second purge, got %v", err)
+	}
+}
+
+func TestAPIKeyRoundTrip(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	owner, err := repo.AddUser(ctx, models.User{Username: "api-key-test-" + strconv.FormatInt(time.Now().UnixNano(), 36)})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", owner.ID) })
+
+	added, err := repo.AddAPIKey(ctx, models.APIKey{
+		Name:    "ci",
+		Prefix:  "msk_test",
+		KeyHash: "hash-" + owner.Username,
+		Scopes:  []models.Scope{models.ScopeMissionsRead, models.ScopeSubmissionsWrite},
+		OwnerID: owner.ID,
+	})
+	if err != nil {
+		t.Fatalf("could not add key: %v", err)
+	}
+
+	got, err := repo.GetAPIKeyByHash(ctx, added.KeyHash)
+	if err != nil {
+		t.Fatalf("could not get key: %v", err)
+	}
+	if len(got.Scopes) != 2 || got.Scopes[1] != models.ScopeSubmissionsWrite || got.LastUsedAt != nil {
+		t.Errorf("unexpected key %+v", got)
+	}
+
+	if err := repo.TouchAPIKey(ctx, added.ID, time.Now()); err != nil {
+		t.Fatalf("could not touch key: %v", err)
+	}
+	if err := repo.RevokeAPIKey(ctx, added.ID); err != nil {
+		t.Fatalf("could not revoke key: %v", err)
+	}
+	keys, err := repo.ListAPIKeys(ctx, owner.ID)
+	if err != nil || len(keys) != 1 || keys[0].LastUsedAt == nil || keys[0].RevokedAt == nil {
+		t.Errorf("expected a used and revoked key, got %+v (%v)", keys, err)
+	}
+	if err := repo.RevokeAPIKey(ctx, -1); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown key, got %v", err)
+	}
+}
+
+func TestAwardAchievementOnce(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	user, err := repo.AddUser(ctx, models.User{Username: "achievement-test-" + strconv.FormatInt(time.Now().UnixNano(), 36)})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID) })
+
+	a := models.Achievement{UserID: user.ID, BadgeID: "first", Name: "First mission", AwardedAt: time.Now()}
+	if added, err := repo.AwardAchievement(ctx, a); err != nil || !added {
+		t.Fatalf("first award: got %v (%v)", added, err)
+	}
+	a.Name = "Renamed"
+	if added, err := repo.AwardAchievement(ctx, a); err != nil || added {
+		t.Errorf("second award: expected no change, got %v (%v)", added, err)
+	}
+
+	got, err := repo.ListAchievements(ctx, user.ID)
+	if err != nil || len(got) != 1 || got[0].Name != "First mission" {
+		t.Errorf("expected the original award, got %+v (%v)", got, err)
+	}
+}
+
+func TestLeaderboardRanks(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36)
+	category := "leaderboard-test-" + suffix
+
+	var missions []int
+	for _, points := range []int{100, 50} {
+		m, err := repo.AddMission(ctx, models.Mission{Title: "Leaderboard", Category: category, Points: points})
+		if err != nil {
+			t.Fatalf("could not add mission: %v", err)
+		}
+		missions = append(missions, m.ID)
+		t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM missions WHERE id = $1", m.ID) })
+	}
+	var users []int
+	for i := range 4 {
+		u, err := repo.AddUser(ctx, models.User{Username: "leaderboard-test-" + strconv.Itoa(i) + "-" + suffix})
+		if err != nil {
+			t.Fatalf("could not add user: %v", err)
+		}
+		users = append(users, u.ID)
+		t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", u.ID) })
+	}
+	for _, c := range [][2]int{{users[0], missions[0]}, {users[0], missions[1]}, {users[1], missions[0]}, {users[2], missions[0]}} {
+		if err := repo.AddCompletion(ctx, c[0], c[1]); err != nil {
+			t.Fatalf("could not add completion: %v", err)
+		}
+	}
+
+	q := service.LeaderboardQuery{Category: category, Limit: 10}
+	entries, err := repo.Leaderboard(ctx, q)
+	if err != nil {
+		t.Fatalf("could not get leaderboard: %v", err)
+	}
+	var ranks []int
+	for _, e := range entries {
+		ranks = append(ranks, e.Rank)
+	}
+	if len(entries) != 3 || entries[0].UserID != users[0] || entries[0].Points != 150 || !slices.Equal(ranks, []int{1, 2, 2}) {
+		t.Errorf("unexpected leaderboard %+v", entries)
+	}
+
+	after := service.LeaderboardCursor{Points: entries[1].Points, UserID: entries[1].UserID}
+	if next, err := repo.Leaderboard(ctx, service.LeaderboardQuery{Category: category, After: &after, Limit: 10}); err != nil || len(next) != 1 || next[0] != entries[2] {
+		t.Errorf("expected the entry after %+v, got %+v (%v)", after, next, err)
+	}
+
+	if e, err := repo.UserRank(ctx, users[2], q); err != nil || e.Rank != 2 || e.Completed != 1 {
+		t.Errorf("expected rank 2, got %+v (%v)", e, err)
+	}
+	if _, err := repo.UserRank(ctx, users[3], q); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unranked user, got %v", err)
+	}
+	for user, want := range map[int]int{users[0]: 150, users[3]: 0} {
+		if points, err := repo.UserPoints(ctx, user, q); err != nil || points != want {
+			t.Errorf("user %d: expected %d points, got %d (%v)", user, want, points, err)
+		}
+	}
+	q.Since = time.Now().Add(time.Hour)
+	if entries, err := repo.Leaderboard(ctx, q); err != nil || len(entries) != 0 {
+		t.Errorf("expected no completions since the future, got %+v (%v)", entries, err)
+	}
+}
+
+func TestStreakRoundTrip(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	user, err := repo.AddUser(ctx, models.User{Username: "streak-test-" + strconv.FormatInt(time.Now().UnixNano(), 36)})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID) })
+
+	if st, err := repo.GetStreak(ctx, user.ID); err != nil || st.Timezone != service.DefaultTimezone || st.LastActive != "" {
+		t.Fatalf("expected an empty streak, got %+v (%v)", st, err)
+	}
+	want := models.Streak{UserID: user.ID, Current: 3, Longest: 5, Freezes: 1, FreezesEarned: 2, LastActive: "2025-07-14", Timezone: "Asia/Tokyo"}
+	if _, err := repo.UpdateStreak(ctx, user.ID, func(models.Streak) (models.Streak, error) { return want, nil }); err != nil {
+		t.Fatalf("could not update streak: %v", err)
+	}
+	if got, err := repo.GetStreak(ctx, user.ID); err != nil || got != want {
+		t.Errorf("expected %+v, got %+v (%v)", want, got, err)
+	}
+
+	failed := errors.New("update failed")
+	if _, err := repo.UpdateStreak(ctx, user.ID, func(st models.Streak) (models.Streak, error) { return st, failed }); !errors.Is(err, failed) {
+		t.Errorf("expected the update error, got %v", err)
+	}
+	if _, err := repo.UpdateStreak(ctx, 1<<30, func(st models.Streak) (models.Streak, error) { return st, nil }); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown user, got %v", err)
+	}
+}
+
+func TestArchiveSeason(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	user, err := repo.AddUser(ctx, models.User{Username: "season-test-" + strconv.FormatInt(time.Now().UnixNano(), 36)})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID) })
+	mission, err := repo.AddMission(ctx, models.Mission{Title: "Season", Points: 70})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM missions WHERE id = $1", mission.ID) })
+	if err := repo.AddCompletion(ctx, user.ID, mission.ID); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	season, err := repo.AddSeason(ctx, models.Season{Name: "Test season", StartsAt: start, EndsAt: start.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("could not add season: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM seasons WHERE id = $1", season.ID) })
+	if _, err := repo.AddSeason(ctx, models.Season{Name: "Duplicate", StartsAt: start, EndsAt: start.Add(time.Hour)}); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("expected ErrConflict for a duplicate start, got %v", err)
+	}
+	if current, err := repo.SeasonAt(ctx, time.Now()); err != nil || current.ID != season.ID {
+		t.Errorf("expected the season running now, got %+v (%v)", current, err)
+	}
+
+	archived, err := repo.ArchiveSeason(ctx, season.ID, time.Now())
+	if err != nil || archived.ArchivedAt == nil {
+		t.Fatalf("could not archive season: %+v (%v)", archived, err)
+	}
+	if _, err := repo.ArchiveSeason(ctx, season.ID, time.Now()); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("expected ErrConflict archiving twice, got %v", err)
+	}
+	if _, err := repo.ArchiveSeason(ctx, 1<<30, time.Now()); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown season, got %v", err)
+	}
+
+	standings, err := repo.SeasonStandings(ctx, season.ID, nil, service.MaxListLimit)
+	if err != nil {
+		t.Fatalf("could not get standings: %v", err)
+	}
+	i := slices.IndexFunc(standings, func(e models.LeaderboardEntry) bool { return e.UserID == user.ID })
+	if i < 0 || standings[i].Points != 70 || standings[i].Username != user.Username || standings[i].Rank < 1 {
+		t.Errorf("expected the user in the final standings, got %+v", standings)
+	}
+
+	// Deleting the user leaves the final standings as they were.
+	if _, err := repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID); err != nil {
+		t.Fatalf("could not delete user: %v", err)
+	}
+	kept, err := repo.SeasonStandings(ctx, season.ID, nil, service.MaxListLimit)
+	if err != nil || !slices.Equal(kept, standings) {
+		t.Errorf("expected unchanged standings %+v, got %+v (%v)", standings, kept, err)
+	}
+}
+
+func TestAddCompletionWithKey(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	user, err := repo.AddUser(ctx, models.User{Username: "idempotency-test-" + strconv.FormatInt(time.Now().UnixNano(), 36)})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID) })
+	mission, err := repo.AddMission(ctx, models.Mission{Title: "Idempotency", Points: 10})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	t.Cleanup(func() { _, _ = repo.DB.ExecContext(ctx, "DELETE FROM missions WHERE id = $1", mission.ID) })
+
+	rec := models.IdempotencyRecord{Key: "k", UserID: user.ID, Path: "/missions/" + strconv.Itoa(mission.ID) + "/complete"}
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = repo.AddCompletionWithKey(ctx, rec, mission.ID)
+		}()
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) || !errors.Is(errors.Join(errs...), service.ErrIdempotencyKeyTaken) {
+		t.Fatalf("expected one completion and one taken key, got %v", errs)
+	}
+	if err := repo.AddCompletion(ctx, user.ID, mission.ID); !errors.Is(err, service.ErrAlreadyCompleted) {
+		t.Errorf("expected ErrAlreadyCompleted, got %v", err)
+	}
+
+	rec.StatusCode, rec.Body = 200, []byte("{}")
+	if err := repo.SaveIdempotencyRecord(ctx, rec); err != nil {
+		t.Fatalf("could not save record: %v", err)
+	}
+	if got, err := repo.GetIdempotencyRecord(ctx, user.ID, "k"); err != nil || got.StatusCode != 200 || string(got.Body) != "{}" {
+		t.Errorf("expected the stored response, got %+v (%v)", got, err)
+	}
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+const seasonColumns = "id, name, starts_at, ends_at, archived_at"
+
+func scanSeason(row interface{ Scan(...any) error }) (models.Season, error) {
+	var s models.Season
+	err := row.Scan(&s.ID, &s.Name, &s.StartsAt, &s.EndsAt, &s.ArchivedAt)
+	return s, mapError(err)
+}
+
+func (r *PostgresRepository) AddSeason(ctx context.Context, s models.Season) (models.Season, error) {
+	return scanSeason(r.DB.QueryRowContext(ctx,
+		"INSERT INTO seasons (name, starts_at, ends_at) VALUES ($1, $2, $3) RETURNING "+seasonColumns,
+		s.Name, s.StartsAt, s.EndsAt,
+	))
+}
+
+func (r *PostgresRepository) GetSeason(ctx context.Context, id int) (models.Season, error) {
+	return scanSeason(r.DB.QueryRowContext(ctx, "SELECT "+seasonColumns+" FROM seasons WHERE id = $1", id))
+}
+
+func (r *PostgresRepository) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+seasonColumns+" FROM seasons ORDER BY starts_at DESC")
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	seasons := []models.Season{}
+	for rows.Next() {
+		s, err := scanSeason(rows)
+		if err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, s)
+	}
+	return seasons, rows.Err()
+}
+
+func (r *PostgresRepository) SeasonAt(ctx context.Context, t time.Time) (models.Season, error) {
+	return scanSeason(r.DB.QueryRowContext(ctx, "SELECT "+seasonColumns+`
+		FROM seasons
+		WHERE starts_at <= $1 AND ends_at > $1
+		ORDER BY starts_at DESC
+		LIMIT 1`, t))
+}
+
+// ArchiveSeason marks the season archived and copies its ranking into
+// season_standings in one transaction. The row lock taken by the update
+// makes concurrent calls wait and then see the season archived.
+func (r *PostgresRepository) ArchiveSeason(ctx context.Context, id int, at time.Time) (models.Season, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Season{}, mapError(err)
+	}
+	defer tx.Rollback()
+
+	s, err := scanSeason(tx.QueryRowContext(ctx,
+		"UPDATE seasons SET archived_at = $2 WHERE id = $1 AND archived_at IS NULL RETURNING "+seasonColumns,
+		id, at,
+	))
+	if errors.Is(err, service.ErrNotFound) {
+		var exists int
+		if err := tx.QueryRowContext(ctx, "SELECT 1 FROM seasons WHERE id = $1", id).Scan(&exists); err != nil {
+			return models.Season{}, mapError(err)
+		}
+		return models.Season{}, service.ErrConflict
+	}
+	if err != nil {
+		return models.Season{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO season_standings (season_id, rank, user_id, username, points, completed)
+		SELECT $4, rank, user_id, username, points, completed
+		FROM (`+rankedScores+`) ranked`,
+		append(leaderboardArgs(service.LeaderboardQuery{Since: s.StartsAt, Until: s.EndsAt}), id)...,
+	)
+	if err != nil {
+		return models.Season{}, mapError(err)
+	}
+	return s, mapError(tx.Commit())
+}
+
+func (r *PostgresRepository) SeasonStandings(ctx context.Context, id int, after *service.LeaderboardCursor, limit int) ([]models.LeaderboardEntry, error) {
+	points, userID := cursorArgs(after)
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT rank, user_id, username, points, completed
+		FROM season_standings
+		WHERE season_id = $1
+			AND ($2::int IS NULL OR points < $2 OR (points = $2 AND user_id > $3))
+		ORDER BY rank, user_id
+		LIMIT $4`,
+		id, points, userID, limit,
+	)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return scanLeaderboard(rows)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+const selectStreak = `
+	SELECT user_id, current_streak, longest_streak, freezes, freezes_earned, last_active, timezone
+	FROM streaks
+	WHERE user_id = $1`
+
+func scanStreak(row *sql.Row) (models.Streak, error) {
+	var st models.Streak
+	var lastActive sql.NullTime
+	err := row.Scan(&st.UserID, &st.Current, &st.Longest, &st.Freezes, &st.FreezesEarned, &lastActive, &st.Timezone)
+	if lastActive.Valid {
+		st.LastActive = lastActive.Time.Format(time.DateOnly)
+	}
+	return st, mapError(err)
+}
+
+func (r *PostgresRepository) GetStreak(ctx context.Context, userID int) (models.Streak, error) {
+	st, err := scanStreak(r.DB.QueryRowContext(ctx, selectStreak, userID))
+	if errors.Is(err, service.ErrNotFound) {
+		return models.Streak{UserID: userID, Timezone: service.DefaultTimezone}, nil
+	}
+	return st, err
+}
+
+// UpdateStreak locks the user's row, creating it if needed, for the whole
+// read-modify-write so that concurrent activity is counted once per day.
+func (r *PostgresRepository) UpdateStreak(ctx context.Context, userID int, update func(models.Streak) (models.Streak, error)) (models.Streak, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Streak{}, mapError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO streaks (user_id) VALUES ($1) ON CONFLICT DO NOTHING", userID); err != nil {
+		return models.Streak{}, mapError(err)
+	}
+	st, err := scanStreak(tx.QueryRowContext(ctx, selectStreak+" FOR UPDATE", userID))
+	if err != nil {
+		return models.Streak{}, err
+	}
+	if st, err = update(st); err != nil {
+		return models.Streak{}, err
+	}
+	st.UserID = userID
+
+	lastActive := sql.NullString{String: st.LastActive, Valid: st.LastActive != ""}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE streaks
+		SET current_streak = $2, longest_streak = $3, freezes = $4, freezes_earned = $5, last_active = $6, timezone = $7
+		WHERE user_id = $1`,
+		userID, st.Current, st.Longest, st.Freezes, st.FreezesEarned, lastActive, st.Timezone,
+	)
+	if err != nil {
+		return models.Streak{}, mapError(err)
+	}
+	return st, mapError(tx.Commit())
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func (r *PostgresRepository) ListTestCases(ctx context.Context, missionID int) ([]models.TestCase, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT id, mission_id, input, expected_output
+		FROM test_cases
+		WHERE mission_id = $1
+		ORDER BY position`, missionID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer rows.Close()
+
+	var cases []models.TestCase
+	for rows.Next() {
+		var tc models.TestCase
+		if err := rows.Scan(&tc.ID, &tc.MissionID, &tc.Input, &tc.ExpectedOutput); err != nil {
+			return nil, mapError(err)
+		}
+		cases = append(cases, tc)
+	}
+	return cases, rows.Err()
+}
+
+func (r *PostgresRepository) ReplaceTestCases(ctx context.Context, missionID int, cases []models.TestCase) ([]models.TestCase, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM missions WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", missionID).Scan(&exists); err != nil {
+		return nil, mapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM test_cases WHERE mission_id = $1", missionID); err != nil {
+		return nil, mapError(err)
+	}
+
+	stored := make([]models.TestCase, len(cases))
+	for i, tc := range cases {
+		tc.MissionID = missionID
+		err := tx.QueryRowContext(
+			ctx,
+			"INSERT INTO test_cases (mission_id, position, input, expected_output) VALUES ($1, $2, $3, $4) RETURNING id",
+			missionID, i, tc.Input, tc.ExpectedOutput,
+		).Scan(&tc.ID)
+		if err != nil {
+			return nil, mapError(err)
+		}
+		stored[i] = tc
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, mapError(err)
+	}
+	return stored, nil
+}
+
+const submissionColumns = `id, user_id, mission_id, language, source, status, verdict, detail,
+	attempts, last_error, created_at, updated_at`
+
+func scanSubmission(row interface{ Scan(...any) error }) (models.Submission, error) {
+	var sub models.Submission
+	err := row.Scan(
+		&sub.ID, &sub.UserID, &sub.MissionID, &sub.Language, &sub.Source, &sub.Status, &sub.Verdict, &sub.Detail,
+		&sub.Attempts, &sub.LastError, &sub.CreatedAt, &sub.UpdatedAt,
+	)
+	return sub, mapError(err)
+}
+
+func (r *PostgresRepository) AddSubmission(ctx context.Context, sub models.Submission) (models.Submission, error) {
+	if sub.Status == "" {
+		sub.Status = models.SubmissionQueued
+	}
+	err := r.DB.QueryRowContext(ctx, `
+		INSERT INTO submissions (user_id, mission_id, language, source, status, verdict, detail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`,
+		sub.UserID, sub.MissionID, sub.Language, sub.Source, sub.Status, sub.Verdict, sub.Detail,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+
+	return sub, mapError(err)
+}
+
+func (r *PostgresRepository) GetSubmission(ctx context.Context, id int) (models.Submission, error) {
+	return scanSubmission(r.DB.QueryRowContext(ctx, "SELECT "+submissionColumns+" FROM submissions WHERE id = $1", id))
+}
+
+// ClaimSubmission uses FOR UPDATE SKIP LOCKED so that concurrent workers,
+// including workers of other replicas, never claim the same row.
+// The lease token returned with the claim guards FinishSubmission and
+// FailSubmission against a worker whose lease was taken over.
+func (r *PostgresRepository) ClaimSubmission(ctx context.Context, lease time.Duration) (models.Submission, error) {
+	row := r.DB.QueryRowContext(ctx, `
+		UPDATE submissions
+		SET status = 'running',
+			attempts = attempts + 1,
+			locked_until = now() + make_interval(secs => $1),
+			lease_token = gen_random_uuid()::text,
+			updated_at = now()
+		WHERE id = (
+			SELECT id FROM submissions
+			WHERE status = 'queued' OR (status = 'running' AND locked_until < now())
+			ORDER BY id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+submissionColumns+`, lease_token`, lease.Seconds())
+
+	var sub models.Submission
+	err := row.Scan(
+		&sub.ID, &sub.UserID, &sub.MissionID, &sub.Language, &sub.Source, &sub.Status, &sub.Verdict, &sub.Detail,
+		&sub.Attempts, &sub.LastError, &sub.CreatedAt, &sub.UpdatedAt, &sub.LeaseToken,
+	)
+	return sub, mapError(err)
+}
+
+func (r *PostgresRepository) FinishSubmission(ctx context.Context, sub models.Submission) error {
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE submissions
+		SET status = 'done', verdict = $1, detail = $2, locked_until = NULL, lease_token = NULL, updated_at = now()
+		WHERE id = $3 AND status = 'running' AND lease_token = $4`,
+		sub.Verdict, sub.Detail, sub.ID, sub.LeaseToken,
+	)
+	return leaseResult(res, err)
+}
+
+func (r *PostgresRepository) FailSubmission(ctx context.Context, sub models.Submission, reason string, retry bool) error {
+	status := models.SubmissionDead
+	if retry {
+		status = models.SubmissionQueued
+	}
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE submissions
+		SET status = $1, last_error = $2, locked_until = NULL, lease_token = NULL, updated_at = now()
+		WHERE id = $3 AND status = 'running' AND lease_token = $4`,
+		status, reason, sub.ID, sub.LeaseToken,
+	)
+	return leaseResult(res, err)
+}
+
+// leaseResult turns an update guarded by a lease token that matched no row into ErrLeaseLost.
+func leaseResult(res sql.Result, err error) error {
+	if err != nil {
+		return mapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return mapError(err)
+	}
+	if n == 0 {
+		return service.ErrLeaseLost
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+func (r *PostgresRepository) GetUser(ctx context.Context, id int) (models.User, error) {
+	var u models.User
+	err := r.DB.QueryRowContext(ctx, "SELECT id, username, role, created_at FROM users WHERE id = $1", id).
+		Scan(&u.ID, &u.Username, &u.Role, &u.CreatedAt)
+	return u, mapError(err)
+}
+
+func (r *PostgresRepository) AddUser(ctx context.Context, u models.User) (models.User, error) {
+	if u.Role == "" {
+		u.Role = models.RolePlayer
+	}
+	err := r.DB.QueryRowContext(
+		ctx,
+		"INSERT INTO users (username, role, password_hash) VALUES ($1, $2, $3) RETURNING id, created_at",
+		u.Username, u.Role, u.PasswordHash,
+	).Scan(&u.ID, &u.CreatedAt)
+
+	return u, mapError(err)
+}
+
+// AddCompletion records that the user completed the mission. The existence
+// checks and the insert run in one transaction; a repeated completion
+// returns service.ErrAlreadyCompleted.
+func (r *PostgresRepository) AddCompletion(ctx context.Context, userID, missionID int) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return mapError(err)
+	}
+	defer tx.Rollback()
+
+	if err := addCompletion(ctx, tx, userID, missionID); err != nil {
+		return err
+	}
+	return mapError(tx.Commit())
+}
+
+func addCompletion(ctx context.Context, tx *sql.Tx, userID, missionID int) error {
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = $1 FOR SHARE", userID).Scan(&exists); err != nil {
+		return mapError(err)
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT 1 FROM missions WHERE id = $1 AND deleted_at IS NULL FOR SHARE", missionID).Scan(&exists); err != nil {
+		return mapError(err)
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO completions (user_id, mission_id) VALUES ($1, $2)", userID, missionID)
+	return mapError(err)
+}
+
+func (r *PostgresRepository) ListCompletedMissions(ctx context.Context, userID int) ([]models.Mission, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT m.id, m.title, m.description, m.difficulty, m.tags, m.category, m.points, m.archived,
+			m.author_id, m.version, m.created_at, m.updated_at, m.published_at, m.deleted_at
+		FROM completions c
+		JOIN missions m ON m.id = c.mission_id
+		WHERE c.user_id = $1
+		ORDER BY c.completed_at`, userID)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return scanMissions(rows)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	MinPasswordLength = 8
+	// MaxPasswordLength is the longest input bcrypt hashes completely.
+	MaxPasswordLength = 72
+	// DefaultRefreshTokenTTL is used when MissionService.RefreshTokenTTL is zero.
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidCredentials  = fmt.Errorf("invalid username or password: %w", ErrUnauthorized)
+	ErrInvalidRefreshToken = fmt.Errorf("invalid refresh token: %w", ErrUnauthorized)
+	// ErrRefreshTokenReused means a rotated refresh token was presented
+	// again; its family has been revoked.
+	ErrRefreshTokenReused = fmt.Errorf("refresh token reused: %w", ErrUnauthorized)
+	ErrUsernameTaken      = fmt.Errorf("username already taken: %w", ErrConflict)
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// dummyHash is compared against when a username does not exist, so that
+// unknown and known usernames take the same time to reject.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Session is the result of a login or refresh: the user and the refresh
+// token to present next time.
+type Session struct {
+	User         models.User
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Register creates a local player account.
+func (s *MissionService) Register(ctx context.Context, username, password string) (models.User, error) {
+	var v ValidationError
+	if !usernamePattern.MatchString(username) {
+		v.add("username", "must be 3 to 32 letters, digits or _.- characters")
+	}
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		v.add("password", "must be between "+strconv.Itoa(MinPasswordLength)+" and "+strconv.Itoa(MaxPasswordLength)+" bytes")
+	}
+	if err := v.err(); err != nil {
+		return models.User{}, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, err
+	}
+	u, err := s.Users.AddUser(ctx, models.User{Username: username, Role: models.RolePlayer, PasswordHash: string(hash)})
+	if errors.Is(err, ErrConflict) {
+		return models.User{}, ErrUsernameTaken
+	}
+	return u, err
+}
+
+// Login checks the password and starts a new refresh token family.
+func (s *MissionService) Login(ctx context.Context, username, password string) (Session, error) {
+	u, err := s.Accounts.GetUserByUsername(ctx, username)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return Session{}, err
+	}
+	hash := dummyHash
+	if err == nil && u.PasswordHash != "" {
+		hash = []byte(u.PasswordHash)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || u.PasswordHash == "" {
+		return Session{}, ErrInvalidCredentials
+	}
+
+	family, err := randomToken()
+	if err != nil {
+		return Session{}, err
+	}
+	return s.issueRefreshToken(ctx, u, family)
+}
+
+// Refresh exchanges a refresh token for its successor. Each token can be
+// used once; presenting a used token again revokes its family, which logs
+// out both the legitimate client and whoever stole the token.
+func (s *MissionService) Refresh(ctx context.Context, token string) (Session, error) {
+	t, err := s.Accounts.GetRefreshToken(ctx, hashToken(token))
+	if errors.Is(err, ErrNotFound) {
+		return Session{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	if t.RevokedAt != nil || !time.Now().Before(t.ExpiresAt) {
+		return Session{}, ErrInvalidRefreshToken
+	}
+
+	err = s.Accounts.UseRefreshToken(ctx, t.ID)
+	if errors.Is(err, ErrConflict) {
+		if err := s.Accounts.RevokeRefreshFamily(ctx, t.FamilyID); err != nil {
+			return Session{}, err
+		}
+		if s.Logger != nil {
+			s.Logger.Warn("refresh token reused, family revoked", "user_id", t.UserID, "family_id", t.FamilyID)
+		}
+		return Session{}, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	u, err := s.Users.GetUser(ctx, t.UserID)
+	if errors.Is(err, ErrNotFound) {
+		return Session{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return Session{}, err
+	}
+	return s.issueRefreshToken(ctx, u, t.FamilyID)
+}
+
+// Logout revokes the family of the refresh token. Unknown tokens are ignored.
+func (s *MissionService) Logout(ctx context.Context, token string) error {
+	t, err := s.Accounts.GetRefreshToken(ctx, hashToken(token))
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.Accounts.RevokeRefreshFamily(ctx, t.FamilyID)
+}
+
+func (s *MissionService) issueRefreshToken(ctx context.Context, u models.User, family string) (Session, error) {
+	token, err := randomToken()
+	if err != nil {
+		return Session{}, err
+	}
+	ttl := s.RefreshTokenTTL
+	if ttl <= 0 {
+		ttl = DefaultRefreshTokenTTL
+	}
+	stored, err := s.Accounts.AddRefreshToken(ctx, models.RefreshToken{
+		UserID:    u.ID,
+		TokenHash: hashToken(token),
+		FamilyID:  family,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{User: u, RefreshToken: token, ExpiresAt: stored.ExpiresAt}, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func newAccountService() *service.MissionService {
+	store := service.NewInMemoryStore()
+	return &service.MissionService{Store: store, Users: store, Accounts: store}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	svc := newAccountService()
+	ctx := context.Background()
+
+	var verr *service.ValidationError
+	if _, err := svc.Register(ctx, "a", "short"); !errors.As(err, &verr) || len(verr.Fields) != 2 {
+		t.Errorf("expected username and password errors, got %v", err)
+	}
+
+	u, err := svc.Register(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("could not register: %v", err)
+	}
+	if u.Role != models.RolePlayer {
+		t.Errorf("expected a player, got %q", u.Role)
+	}
+	if _, err := svc.Register(ctx, "alice", "another password"); !errors.Is(err, service.ErrUsernameTaken) {
+		t.Errorf("expected ErrUsernameTaken, got %v", err)
+	}
+
+	if _, err := svc.Login(ctx, "alice", "wrong password"); !errors.Is(err, service.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials for a wrong password, got %v", err)
+	}
+	if _, err := svc.Login(ctx, "bob", "correct horse"); !errors.Is(err, service.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials for an unknown user, got %v", err)
+	}
+	// The seeded demo user has no password and cannot log in.
+	if _, err := svc.Login(ctx, "demo", ""); !errors.Is(err, service.ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials for a user without password, got %v", err)
+	}
+
+	session, err := svc.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("could not log in: %v", err)
+	}
+	if session.User.ID != u.ID || session.RefreshToken == "" {
+		t.Errorf("unexpected session %+v", session)
+	}
+}
+
+func TestRefreshRotationAndReuse(t *testing.T) {
+	svc := newAccountService()
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, "alice", "correct horse"); err != nil {
+		t.Fatalf("could not register: %v", err)
+	}
+	first, err := svc.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("could not log in: %v", err)
+	}
+
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("could not refresh: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("expected a rotated refresh token")
+	}
+
+	// Replaying the first token revokes the family, including its successor.
+	if _, err := svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, service.ErrRefreshTokenReused) {
+		t.Errorf("expected ErrRefreshTokenReused, got %v", err)
+	}
+	if _, err := svc.Refresh(ctx, second.RefreshToken); !errors.Is(err, service.ErrInvalidRefreshToken) {
+		t.Errorf("expected the successor to be revoked, got %v", err)
+	}
+
+	other, err := svc.Login(ctx, "alice", "correct horse")
+	if err != nil {
+		t.Fatalf("could not log in: %v", err)
+	}
+	if err := svc.Logout(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("could not log out: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, other.RefreshToken); !errors.Is(err, service.ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken after logout, got %v", err)
+	}
+	if _, err := svc.Refresh(ctx, "unknown"); !errors.Is(err, service.ErrInvalidRefreshToken) {
+		t.Errorf("expected ErrInvalidRefreshToken for an unknown token, got %v", err)
+	}
+}
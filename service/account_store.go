@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// AccountStore keeps local credentials and refresh tokens.
+type AccountStore interface {
+	// GetUserByUsername returns the user with the given username, including
+	// its password hash.
+	GetUserByUsername(ctx context.Context, username string) (models.User, error)
+	AddRefreshToken(ctx context.Context, t models.RefreshToken) (models.RefreshToken, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error)
+	// UseRefreshToken marks the token as used. It returns ErrConflict when
+	// it was used before, so that only one caller can rotate a token.
+	UseRefreshToken(ctx context.Context, id int) error
+	// RevokeRefreshFamily revokes every token of the family.
+	RevokeRefreshFamily(ctx context.Context, familyID string) error
+}
+
+var _ AccountStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) GetUserByUsername(ctx context.Context, username string) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			return u, nil
+		}
+	}
+	return models.User{}, ErrNotFound
+}
+
+func (s *InMemoryStore) AddRefreshToken(ctx context.Context, t models.RefreshToken) (models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRefreshTokenID++
+	t.ID = s.nextRefreshTokenID
+	t.CreatedAt = time.Now()
+	s.refreshTokens = append(s.refreshTokens, t)
+	return t, nil
+}
+
+func (s *InMemoryStore) GetRefreshToken(ctx context.Context, tokenHash string) (models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.refreshTokens {
+		if t.TokenHash == tokenHash {
+			return t, nil
+		}
+	}
+	return models.RefreshToken{}, ErrNotFound
+}
+
+func (s *InMemoryStore) UseRefreshToken(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.refreshTokens {
+		t := &s.refreshTokens[i]
+		if t.ID != id {
+			continue
+		}
+		if t.UsedAt != nil {
+			return ErrConflict
+		}
+		now := time.Now()
+		t.UsedAt = &now
+		return nil
+	}
+	return ErrNotFound
+}
+
+func (s *InMemoryStore) RevokeRefreshFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for i := range s.refreshTokens {
+		if t := &s.refreshTokens[i]; t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// AchievementStore persists the badges awarded to users.
+type AchievementStore interface {
+	// AwardAchievement stores a. It reports false without an error when
+	// the user already has the badge.
+	AwardAchievement(ctx context.Context, a models.Achievement) (bool, error)
+	// ListAchievements returns the user's achievements in award order.
+	ListAchievements(ctx context.Context, userID int) ([]models.Achievement, error)
+}
+
+var _ AchievementStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) AwardAchievement(ctx context.Context, a models.Achievement) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findUser(a.UserID) < 0 {
+		return false, ErrNotFound
+	}
+	for _, existing := range s.achievements {
+		if existing.UserID == a.UserID && existing.BadgeID == a.BadgeID {
+			return false, nil
+		}
+	}
+	s.achievements = append(s.achievements, a)
+	return true, nil
+}
+
+func (s *InMemoryStore) ListAchievements(ctx context.Context, userID int) ([]models.Achievement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	achievements := []models.Achievement{}
+	for _, a := range s.achievements {
+		if a.UserID == userID {
+			achievements = append(achievements, a)
+		}
+	}
+	return achievements, nil
+}
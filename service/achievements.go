@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// publish hands a domain event to the achievement engine. Failures are
+// logged and never fail the operation that raised the event; badges missed
+// that way, or earned without an event, are caught up with the user's next
+// event.
+func (s *MissionService) publish(ctx context.Context, e models.Event) {
+	if s.Achievements == nil {
+		return
+	}
+	if e.At.IsZero() {
+		e.At = s.now()
+	}
+	completed, err := s.Users.ListCompletedMissions(ctx, e.UserID)
+	if err == nil {
+		err = s.awardAchievements(ctx, e.UserID, completed, &e)
+	}
+	if err != nil && s.Logger != nil {
+		s.Logger.Warn("could not award achievements", "user_id", e.UserID, "event", e.Type, "error", err)
+	}
+}
+
+// awardAchievements stores every badge the user has earned but not been
+// awarded yet. Without an event only badges that do not depend on one are
+// checked.
+func (s *MissionService) awardAchievements(ctx context.Context, userID int, completed []models.Mission, e *models.Event) error {
+	awarded, err := s.Achievements.ListAchievements(ctx, userID)
+	if err != nil {
+		return err
+	}
+	at := s.now()
+	if e != nil {
+		at = e.At
+	}
+
+	for _, b := range s.GamificationRules().Badges {
+		if slices.ContainsFunc(awarded, func(a models.Achievement) bool { return a.BadgeID == b.ID }) {
+			continue
+		}
+		ok, err := s.badgeEarned(ctx, b, completed, e)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		added, err := s.Achievements.AwardAchievement(ctx, models.Achievement{UserID: userID, BadgeID: b.ID, Name: b.Name, AwardedAt: at})
+		if err != nil {
+			return err
+		}
+		if added && s.Logger != nil {
+			s.Logger.Info("achievement awarded", "user_id", userID, "badge", b.ID)
+		}
+	}
+	return nil
+}
+
+// badgeEarned reports whether the user with the completed missions has
+// earned b, checking event conditions against e when it is set.
+func (s *MissionService) badgeEarned(ctx context.Context, b models.Badge, completed []models.Mission, e *models.Event) (bool, error) {
+	if b.Event != "" && (e == nil || e.Type != b.Event) {
+		return false, nil
+	}
+	matches := func(m models.Mission) bool {
+		return (b.Category == "" || m.Category == b.Category) && (b.Difficulty == "" || m.Difficulty == b.Difficulty)
+	}
+
+	if b.SolvedWithin > 0 {
+		if e == nil || e.Type != models.EventMissionCompleted {
+			return false, nil
+		}
+		i := slices.IndexFunc(completed, func(m models.Mission) bool { return m.ID == e.MissionID })
+		if i < 0 || !matches(completed[i]) || e.At.Sub(completed[i].PublishedAt) > time.Duration(b.SolvedWithin) {
+			return false, nil
+		}
+	}
+	if b.MinStreak > 0 && (e == nil || e.Type != models.EventStreakExtended || e.Streak < b.MinStreak) {
+		return false, nil
+	}
+
+	points, count := 0, 0
+	done := make(map[int]bool)
+	for _, m := range completed {
+		if matches(m) {
+			points += m.Points
+			count++
+			done[m.ID] = true
+		}
+	}
+	if points < b.MinPoints || count < b.MinCompleted {
+		return false, nil
+	}
+	if b.AllInCategory {
+		return s.completedAll(ctx, ListOptions{Category: b.Category, Difficulty: b.Difficulty}, done)
+	}
+	return true, nil
+}
+
+// completedAll reports whether done contains every current mission listed
+// with opts, which must list at least one mission.
+func (s *MissionService) completedAll(ctx context.Context, opts ListOptions, done map[int]bool) (bool, error) {
+	opts.Limit = MaxListLimit
+	seen := 0
+	for {
+		page, err := s.Store.ListMissions(ctx, opts)
+		if err != nil {
+			return false, err
+		}
+		for _, m := range page.Items {
+			if !done[m.ID] {
+				return false, nil
+			}
+			seen++
+		}
+		if page.NextCursor == "" {
+			return seen > 0, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+type acceptingJudge struct{}
+
+func (acceptingJudge) Judge(ctx context.Context, sub models.Submission, cases []models.TestCase) (service.JudgeResult, error) {
+	return service.JudgeResult{Verdict: models.VerdictAccepted}, nil
+}
+
+func (acceptingJudge) Supports(language string) bool { return true }
+
+func TestAchievementEngine(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{
+		Store: store, Users: store, Submissions: store, Achievements: store, Judge: acceptingJudge{},
+		Rules: models.GamificationRules{
+			Levels: []models.Level{{Name: "Beginner"}},
+			Badges: []models.Badge{
+				{ID: "first", Name: "First mission", MinCompleted: 1},
+				{ID: "fast", Name: "Quick draw", SolvedWithin: models.Duration(time.Hour)},
+				{ID: "instant", Name: "Too fast", SolvedWithin: 1},
+				{ID: "basics", Name: "Basics done", AllInCategory: true, Category: "basics"},
+				{ID: "accepted", Name: "Judge approved", Event: models.EventSubmissionAccepted},
+				{ID: "hard", Name: "Hard worker", MinCompleted: 5, Difficulty: models.DifficultyHard},
+			},
+		},
+	}
+	ctx := context.Background()
+	badges := func(p models.Profile) []string {
+		ids := []string{}
+		for _, b := range p.Badges {
+			ids = append(ids, b.BadgeID)
+		}
+		return ids
+	}
+
+	profile, err := svc.CompleteMission(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if want := []string{"first", "fast"}; !reflect.DeepEqual(badges(profile), want) {
+		t.Errorf("after first mission: expected %v, got %v", want, badges(profile))
+	}
+
+	// The second basics mission is solved through an accepted submission.
+	if _, err := svc.SubmitSolution(ctx, models.Submission{UserID: 1, MissionID: 2, Language: "go", Source: "package main"}); err != nil {
+		t.Fatalf("could not submit solution: %v", err)
+	}
+	sub, err := store.ClaimSubmission(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("could not claim submission: %v", err)
+	}
+	if _, err := svc.ProcessSubmission(ctx, sub); err != nil {
+		t.Fatalf("could not process submission: %v", err)
+	}
+	profile, err = svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("could not get profile: %v", err)
+	}
+	if want := []string{"first", "fast", "basics", "accepted"}; !reflect.DeepEqual(badges(profile), want) {
+		t.Errorf("after submission: expected %v, got %v", want, badges(profile))
+	}
+	if profile.Badges[0].AwardedAt.IsZero() || len(profile.Achievements) != 4 || profile.Achievements[0] != "First mission" {
+		t.Errorf("unexpected achievements %+v", profile)
+	}
+
+	// Awarded badges stay when the rules no longer contain them. Reading the
+	// profile awards nothing; badges of the new rules come with the next event.
+	svc.Rules = service.DefaultRules()
+	profile, err = svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("could not get profile: %v", err)
+	}
+	if want := []string{"first", "fast", "basics", "accepted"}; !reflect.DeepEqual(badges(profile), want) {
+		t.Errorf("after rule change: expected %v, got %v", want, badges(profile))
+	}
+	extra, err := store.AddMission(ctx, models.Mission{Title: "Extra", Points: 10})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	if profile, err = svc.CompleteMission(ctx, 1, extra.ID); err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if want := []string{"first", "fast", "basics", "accepted", "points-200"}; !reflect.DeepEqual(badges(profile), want) {
+		t.Errorf("after the next event: expected %v, got %v", want, badges(profile))
+	}
+}
+
+func TestSolvedWithinCountsFromPublication(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{
+		Store: store, Users: store, Achievements: store,
+		Rules: models.GamificationRules{
+			Levels: []models.Level{{Name: "Beginner"}},
+			Badges: []models.Badge{{ID: "fast", Name: "Quick draw", SolvedWithin: models.Duration(time.Hour)}},
+		},
+	}
+	ctx := context.Background()
+
+	// A mission restored from the trash counts as published again.
+	mission, err := store.AddMission(ctx, models.Mission{Title: "Old mission", Points: 10})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	if err := store.DeleteMission(ctx, mission.ID, 0); err != nil {
+		t.Fatalf("could not delete mission: %v", err)
+	}
+	restored, err := store.RestoreMission(ctx, mission.ID)
+	if err != nil {
+		t.Fatalf("could not restore mission: %v", err)
+	}
+	if !restored.PublishedAt.After(mission.PublishedAt) {
+		t.Fatalf("expected restoring to publish the mission again, got %v", restored.PublishedAt)
+	}
+	// Exactly the allowed hour after the restore, but more after the creation.
+	svc.Clock = &fakeClock{now: restored.PublishedAt.Add(time.Hour)}
+	profile, err := svc.CompleteMission(ctx, 1, mission.ID)
+	if err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if len(profile.Badges) != 1 || profile.Badges[0].BadgeID != "fast" {
+		t.Errorf("expected the fast badge, got %+v", profile.Badges)
+	}
+}
+
+func TestAchievementRulesValidation(t *testing.T) {
+	var verr *service.ValidationError
+	err := service.ValidateRules(models.GamificationRules{
+		Levels: []models.Level{{Name: "Beginner"}},
+		Badges: []models.Badge{
+			{ID: "all", Name: "All of them", AllInCategory: true},
+			{ID: "event", Name: "Unknown event", Event: "mission_deleted"},
+			{ID: "streak", Name: "Negative streak", MinStreak: -1},
+		},
+	})
+	if !errors.As(err, &verr) || len(verr.Fields) != 3 {
+		t.Errorf("expected three field errors, got %v", err)
+	}
+}
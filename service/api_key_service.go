@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+const (
+	// APIKeyPrefix starts every API key so that leaked keys are easy to
+	// recognise in logs and secret scanners.
+	APIKeyPrefix = "msk_"
+	// apiKeyTouchInterval limits how often LastUsedAt is written for a
+	// busy key.
+	apiKeyTouchInterval = time.Minute
+	maxAPIKeyNameLength = 100
+)
+
+var ErrInvalidAPIKey = fmt.Errorf("invalid API key: %w", ErrUnauthorized)
+
+// CreateAPIKey mints a key for the caller and returns it together with the
+// secret, which is not stored and cannot be shown again.
+func (s *MissionService) CreateAPIKey(ctx context.Context, name string, scopes []models.Scope) (models.APIKey, string, error) {
+	p, err := keyManager(ctx)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	var v ValidationError
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		v.add("name", "must be between 1 and 100 characters")
+	}
+	if len(scopes) == 0 {
+		v.add("scopes", "must not be empty")
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			v.add("scopes", fmt.Sprintf("unknown scope %q", scope))
+		}
+	}
+	if err := v.err(); err != nil {
+		return models.APIKey{}, "", err
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	secret := APIKeyPrefix + token
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	k, err := s.APIKeys.AddAPIKey(ctx, models.APIKey{
+		Name:    name,
+		Prefix:  secret[:len(APIKeyPrefix)+8],
+		KeyHash: hashToken(secret),
+		Scopes:  slices.Compact(scopes),
+		OwnerID: p.UserID,
+	})
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	return k, secret, nil
+}
+
+// ListAPIKeys returns the caller's keys; admins see every key.
+func (s *MissionService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	p, err := keyManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner := p.UserID
+	if p.Role == models.RoleAdmin {
+		owner = 0
+	}
+	return s.APIKeys.ListAPIKeys(ctx, owner)
+}
+
+// RevokeAPIKey revokes one of the caller's keys; admins may revoke any key.
+// Keys of other users are reported as ErrNotFound, so that their IDs
+// cannot be probed.
+func (s *MissionService) RevokeAPIKey(ctx context.Context, id int) error {
+	p, err := keyManager(ctx)
+	if err != nil {
+		return err
+	}
+	k, err := s.APIKeys.GetAPIKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	if k.OwnerID != p.UserID && p.Role != models.RoleAdmin {
+		return ErrNotFound
+	}
+	return s.APIKeys.RevokeAPIKey(ctx, id)
+}
+
+// AuthenticateAPIKey returns the principal of the key's owner limited to
+// the key's scopes, and records when the key was last used. Without an
+// APIKeyStore no key is valid.
+func (s *MissionService) AuthenticateAPIKey(ctx context.Context, secret string) (models.Principal, error) {
+	if s.APIKeys == nil {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+	k, err := s.APIKeys.GetAPIKeyByHash(ctx, hashToken(secret))
+	if errors.Is(err, ErrNotFound) {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+	if k.RevokedAt != nil {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+
+	u, err := s.Users.GetUser(ctx, k.OwnerID)
+	if errors.Is(err, ErrNotFound) {
+		return models.Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return models.Principal{}, err
+	}
+
+	now := time.Now()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.APIKeys.TouchAPIKey(ctx, k.ID, now); err != nil && s.Logger != nil {
+			s.Logger.Warn("could not record API key use", "api_key_id", k.ID, "error", err)
+		}
+	}
+
+	return models.Principal{UserID: u.ID, Username: u.Username, Role: u.Role, APIKeyID: k.ID, Scopes: k.Scopes}, nil
+}
+
+// keyManager returns the caller in ctx. API keys are managed by users
+// only; a key cannot mint, list or revoke keys.
+func keyManager(ctx context.Context) (models.Principal, error) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return models.Principal{}, ErrUnauthorized
+	}
+	if p.APIKeyID != 0 {
+		return models.Principal{}, ErrForbidden
+	}
+	return p, nil
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestAPIKeys(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, APIKeys: store}
+	bot, err := store.AddUser(context.Background(), models.User{Username: "ci-bot", Role: models.RoleAuthor})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	as := func(p models.Principal) context.Context {
+		return service.WithPrincipal(context.Background(), p)
+	}
+	owner := as(models.Principal{UserID: bot.ID, Role: models.RoleAuthor})
+	scopes := []models.Scope{models.ScopeMissionsRead, models.ScopeSubmissionsWrite}
+
+	if _, _, err := svc.CreateAPIKey(context.Background(), "ci", scopes); !errors.Is(err, service.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without a principal, got %v", err)
+	}
+	var verr *service.ValidationError
+	if _, _, err := svc.CreateAPIKey(owner, "ci", []models.Scope{"missions:delete"}); !errors.As(err, &verr) {
+		t.Errorf("expected a validation error for an unknown scope, got %v", err)
+	}
+
+	key, secret, err := svc.CreateAPIKey(owner, "ci", scopes)
+	if err != nil {
+		t.Fatalf("could not create key: %v", err)
+	}
+	if key.OwnerID != bot.ID || key.Prefix == "" || secret[:len(key.Prefix)] != key.Prefix {
+		t.Errorf("unexpected key %+v for secret %q", key, secret)
+	}
+
+	p, err := svc.AuthenticateAPIKey(context.Background(), secret)
+	if err != nil {
+		t.Fatalf("could not authenticate: %v", err)
+	}
+	if p.UserID != bot.ID || p.Role != models.RoleAuthor || p.APIKeyID != key.ID {
+		t.Errorf("unexpected principal %+v", p)
+	}
+	if !p.HasScope(models.ScopeMissionsRead) || p.HasScope(models.ScopeMissionsWrite) {
+		t.Errorf("unexpected scopes %v", p.Scopes)
+	}
+	if keys, _ := svc.ListAPIKeys(owner); len(keys) != 1 || keys[0].LastUsedAt == nil {
+		t.Errorf("expected one key with a last use, got %+v", keys)
+	}
+
+	// Keys cannot manage keys, and users cannot revoke keys of others.
+	if _, _, err := svc.CreateAPIKey(as(p), "nested", scopes); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for a key minting keys, got %v", err)
+	}
+	other := as(models.Principal{UserID: 1, Role: models.RolePlayer})
+	if err := svc.RevokeAPIKey(other, key.ID); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for another user, got %v", err)
+	}
+
+	if err := svc.RevokeAPIKey(owner, key.ID); err != nil {
+		t.Fatalf("could not revoke key: %v", err)
+	}
+	if _, err := svc.AuthenticateAPIKey(context.Background(), secret); !errors.Is(err, service.ErrInvalidAPIKey) {
+		t.Errorf("expected ErrInvalidAPIKey after revocation, got %v", err)
+	}
+}
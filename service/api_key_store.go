@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// APIKeyStore keeps API keys by the hash of their secret.
+type APIKeyStore interface {
+	AddAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, error)
+	GetAPIKey(ctx context.Context, id int) (models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error)
+	// ListAPIKeys returns the keys of the owner, or every key when ownerID is 0.
+	ListAPIKeys(ctx context.Context, ownerID int) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+}
+
+var _ APIKeyStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) AddAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findUser(k.OwnerID) < 0 {
+		return models.APIKey{}, ErrNotFound
+	}
+	s.nextAPIKeyID++
+	k.ID = s.nextAPIKeyID
+	k.Scopes = slices.Clone(k.Scopes)
+	k.CreatedAt = time.Now()
+	s.apiKeys = append(s.apiKeys, k)
+	return k, nil
+}
+
+func (s *InMemoryStore) GetAPIKey(ctx context.Context, id int) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.apiKeys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return models.APIKey{}, ErrNotFound
+}
+
+func (s *InMemoryStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.apiKeys {
+		if k.KeyHash == keyHash {
+			return k, nil
+		}
+	}
+	return models.APIKey{}, ErrNotFound
+}
+
+func (s *InMemoryStore) ListAPIKeys(ctx context.Context, ownerID int) ([]models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := []models.APIKey{}
+	for _, k := range s.apiKeys {
+		if ownerID == 0 || k.OwnerID == ownerID {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (s *InMemoryStore) RevokeAPIKey(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.apiKeys {
+		if k := &s.apiKeys[i]; k.ID == id {
+			if k.RevokedAt == nil {
+				now := time.Now()
+				k.RevokedAt = &now
+			}
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (s *InMemoryStore) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.apiKeys {
+		if k := &s.apiKeys[i]; k.ID == id {
+			k.LastUsedAt = &usedAt
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// canEditMission reports whether the caller in ctx may change m: admins may
+// change every mission, authors only the missions they created.
+func canEditMission(ctx context.Context, m models.Mission) bool {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return false
+	}
+	switch p.Role {
+	case models.RoleAdmin:
+		return true
+	case models.RoleAuthor:
+		return m.AuthorID != 0 && m.AuthorID == p.UserID
+	}
+	return false
+}
+
+// editableMission returns the live mission if the caller in ctx may change
+// it and ErrForbidden otherwise.
+func (s *MissionService) editableMission(ctx context.Context, id int) (models.Mission, error) {
+	m, err := s.Store.GetByID(ctx, id)
+	if err != nil {
+		return models.Mission{}, err
+	}
+	if !canEditMission(ctx, m) {
+		return models.Mission{}, ErrForbidden
+	}
+	return m, nil
+}
+
+// canManageUser reports whether the caller in ctx may change the settings
+// of the user: users may change their own, admins everyone's.
+func canManageUser(ctx context.Context, userID int) bool {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return false
+	}
+	return p.UserID == userID || p.Role == models.RoleAdmin
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain errors returned by services and stores. Callers should match them
+// with errors.Is; more specific errors wrap one of them.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized means the caller could not be authenticated.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden means the caller is not allowed to perform the operation.
+	ErrForbidden = errors.New("forbidden")
+	// ErrPreconditionFailed means a conditional request did not match the current state.
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// ErrAlreadyCompleted is returned when a user tries to complete a mission twice.
+var ErrAlreadyCompleted = fmt.Errorf("mission already completed: %w", ErrConflict)
+
+// ErrIdempotencyKeyTaken is returned when an Idempotency-Key was already
+// used by an earlier request of the same user.
+var ErrIdempotencyKeyTaken = fmt.Errorf("idempotency key already used: %w", ErrConflict)
+
+// ErrVersionMismatch is returned when a mission was modified since the version the client read.
+var ErrVersionMismatch = fmt.Errorf("mission version mismatch: %w", ErrPreconditionFailed)
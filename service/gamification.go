@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pseudoerr/mission-service/models"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRules returns the rules used when no rules file is configured.
+func DefaultRules() models.GamificationRules {
+	return models.GamificationRules{
+		Levels: []models.Level{
+			{Name: "Beginner", MinPoints: 0},
+			{Name: "Intermediate", MinPoints: 200},
+			{Name: "Advanced", MinPoints: 500},
+			{Name: "Expert", MinPoints: 1000},
+		},
+		Badges: []models.Badge{
+			{ID: "points-200", Name: "🏅200+ points", MinPoints: 200},
+			{ID: "points-500", Name: "🎖 500+ points", MinPoints: 500},
+			{ID: "points-1000", Name: "🏆 1000+ points", MinPoints: 1000},
+		},
+		Streaks: models.StreakRules{FreezePoints: 500, MaxFreezes: 2},
+	}
+}
+
+// LoadRules reads and validates a rules file. Files ending in .yaml or .yml
+// are read as YAML, everything else as JSON; unknown fields are rejected.
+func LoadRules(path string) (models.GamificationRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return models.GamificationRules{}, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		// Going through JSON keeps a single set of field names and the
+		// same strict decoding for both formats.
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return models.GamificationRules{}, fmt.Errorf("%s: %w", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return models.GamificationRules{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var rules models.GamificationRules
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rules); err != nil {
+		return models.GamificationRules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateRules(rules); err != nil {
+		return models.GamificationRules{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ValidateRules checks that levels start at 0 points and strictly increase,
+// that every badge has a unique ID, a name and consistent conditions and
+// that streak freezes are capped.
+func ValidateRules(rules models.GamificationRules) error {
+	var v ValidationError
+	if len(rules.Levels) == 0 {
+		v.add("levels", "must not be empty")
+	}
+	names := make(map[string]bool)
+	for i, l := range rules.Levels {
+		field := fmt.Sprintf("levels[%d]", i)
+		if l.Name == "" {
+			v.add(field+".name", "must not be empty")
+		} else if names[l.Name] {
+			v.add(field+".name", "must be unique")
+		}
+		names[l.Name] = true
+		switch {
+		case i == 0 && l.MinPoints != 0:
+			v.add(field+".min_points", "the first level must start at 0")
+		case i > 0 && l.MinPoints <= rules.Levels[i-1].MinPoints:
+			v.add(field+".min_points", "must be greater than the previous level's")
+		}
+	}
+
+	ids := make(map[string]bool)
+	for i, b := range rules.Badges {
+		field := fmt.Sprintf("badges[%d]", i)
+		if b.ID == "" {
+			v.add(field+".id", "must not be empty")
+		} else if ids[b.ID] {
+			v.add(field+".id", "must be unique")
+		}
+		ids[b.ID] = true
+		if b.Name == "" {
+			v.add(field+".name", "must not be empty")
+		}
+		if b.MinPoints < 0 || b.MinCompleted < 0 || b.SolvedWithin < 0 || b.MinStreak < 0 {
+			v.add(field, "conditions must not be negative")
+		}
+		if b.Event == "" && b.MinPoints == 0 && b.MinCompleted == 0 && !b.AllInCategory && b.SolvedWithin == 0 && b.MinStreak == 0 {
+			v.add(field, "needs at least one condition")
+		}
+		if b.Event != "" && !b.Event.Valid() {
+			v.add(field+".event", "must be one of mission_completed, submission_accepted, streak_extended")
+		}
+		if b.AllInCategory && b.Category == "" {
+			v.add(field+".category", "is required by all_in_category")
+		}
+		switch b.Difficulty {
+		case "", models.DifficultyEasy, models.DifficultyMedium, models.DifficultyHard:
+		default:
+			v.add(field+".difficulty", "must be one of easy, medium, hard")
+		}
+	}
+
+	switch {
+	case rules.Streaks.FreezePoints < 0 || rules.Streaks.MaxFreezes < 0:
+		v.add("streaks", "must not be negative")
+	case rules.Streaks.FreezePoints > 0 && rules.Streaks.MaxFreezes == 0:
+		v.add("streaks.max_freezes", "is required by freeze_points")
+	}
+	return v.err()
+}
+
+// GamificationRules returns the configured rules or DefaultRules.
+func (s *MissionService) GamificationRules() models.GamificationRules {
+	if len(s.Rules.Levels) == 0 {
+		return DefaultRules()
+	}
+	return s.Rules
+}
+
+// level returns the name of the last level reached with total points.
+func level(rules models.GamificationRules, total int) string {
+	name := ""
+	for _, l := range rules.Levels {
+		if total >= l.MinPoints {
+			name = l.Name
+		}
+	}
+	return name
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestDefaultRulesKeepThresholds(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store}
+	ctx := context.Background()
+
+	for _, id := range []int{1, 2} {
+		if err := store.AddCompletion(ctx, 1, id); err != nil {
+			t.Fatalf("could not add completion: %v", err)
+		}
+	}
+	profile, err := svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := models.Profile{TotalPoints: 300, Level: "Intermediate", Achievements: []string{"🏅200+ points"}}
+	if !reflect.DeepEqual(profile, want) {
+		t.Errorf("expected %+v, got %+v", want, profile)
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Rules: models.GamificationRules{
+		Levels: []models.Level{{Name: "Rookie"}, {Name: "Pro", MinPoints: 100}},
+		Badges: []models.Badge{
+			{ID: "medium", Name: "Medium rare", MinCompleted: 1, Difficulty: models.DifficultyMedium},
+			{ID: "basics", Name: "Basics done", MinCompleted: 2, Category: "basics"},
+		},
+	}}
+	ctx := context.Background()
+
+	if err := store.AddCompletion(ctx, 1, 1); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+	profile, err := svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Level != "Pro" || len(profile.Achievements) != 0 {
+		t.Errorf("unexpected profile %+v", profile)
+	}
+
+	if err := store.AddCompletion(ctx, 1, 2); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+	profile, err = svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"Medium rare", "Basics done"}; !reflect.DeepEqual(profile.Achievements, want) {
+		t.Errorf("expected badges %v, got %v", want, profile.Achievements)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+		return path
+	}
+
+	yamlRules, err := service.LoadRules(write("rules.yaml", `
+levels:
+  - {name: Rookie, min_points: 0}
+  - {name: Pro, min_points: 300}
+badges:
+  - {id: hard, name: Hardened, min_completed: 3, difficulty: hard}
+`))
+	if err != nil {
+		t.Fatalf("could not load YAML rules: %v", err)
+	}
+	jsonRules, err := service.LoadRules(write("rules.json", `{
+		"levels": [{"name": "Rookie", "min_points": 0}, {"name": "Pro", "min_points": 300}],
+		"badges": [{"id": "hard", "name": "Hardened", "min_completed": 3, "difficulty": "hard"}]
+	}`))
+	if err != nil {
+		t.Fatalf("could not load JSON rules: %v", err)
+	}
+	if !reflect.DeepEqual(yamlRules, jsonRules) || jsonRules.Badges[0].Difficulty != models.DifficultyHard {
+		t.Errorf("YAML and JSON rules differ: %+v vs %+v", yamlRules, jsonRules)
+	}
+
+	if _, err := service.LoadRules(write("unknown.yaml", "levels: [{name: Rookie, min_points: 0}]\nranks: []\n")); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+
+	var verr *service.ValidationError
+	_, err = service.LoadRules(write("invalid.yaml", `
+levels:
+  - {name: Rookie, min_points: 10}
+  - {name: Pro, min_points: 5}
+badges:
+  - {id: none, name: Nothing}
+`))
+	if !errors.As(err, &verr) || len(verr.Fields) != 3 {
+		t.Errorf("expected three field errors, got %v", err)
+	}
+
+	if err := service.ValidateRules(service.DefaultRules()); err != nil {
+		t.Errorf("default rules are invalid: %v", err)
+	}
+	if _, err := service.LoadRules("../config/gamification.example.yaml"); err != nil {
+		t.Errorf("example rules are invalid: %v", err)
+	}
+}
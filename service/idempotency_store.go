@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// IdempotencyStore keeps responses of completed requests so that retries
+// with the same Idempotency-Key can be answered without re-executing them.
+type IdempotencyStore interface {
+	GetIdempotencyRecord(ctx context.Context, userID int, key string) (models.IdempotencyRecord, error)
+	// AddCompletionWithKey records that rec.UserID completed the mission and
+	// reserves rec.Key in the same transaction, so that a reserved key always
+	// means the completion happened. It returns ErrIdempotencyKeyTaken, and
+	// records nothing, when the key is already reserved.
+	AddCompletionWithKey(ctx context.Context, rec models.IdempotencyRecord, missionID int) error
+	// SaveIdempotencyRecord stores the response of a reserved key.
+	SaveIdempotencyRecord(ctx context.Context, rec models.IdempotencyRecord) error
+}
+
+var _ IdempotencyStore = (*InMemoryStore)(nil)
+
+type idempotencyKey struct {
+	userID int
+	key    string
+}
+
+func (s *InMemoryStore) GetIdempotencyRecord(ctx context.Context, userID int, key string) (models.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.idempotency[idempotencyKey{userID: userID, key: key}]
+	if !ok {
+		return models.IdempotencyRecord{}, ErrNotFound
+	}
+	return rec, nil
+}
+
+func (s *InMemoryStore) AddCompletionWithKey(ctx context.Context, rec models.IdempotencyRecord, missionID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := idempotencyKey{userID: rec.UserID, key: rec.Key}
+	if _, ok := s.idempotency[k]; ok {
+		return ErrIdempotencyKeyTaken
+	}
+	if err := s.addCompletion(rec.UserID, missionID); err != nil {
+		return err
+	}
+	if s.idempotency == nil {
+		s.idempotency = make(map[idempotencyKey]models.IdempotencyRecord)
+	}
+	s.idempotency[k] = models.IdempotencyRecord{Key: rec.Key, UserID: rec.UserID, Path: rec.Path, CreatedAt: time.Now()}
+	return nil
+}
+
+func (s *InMemoryStore) SaveIdempotencyRecord(ctx context.Context, rec models.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := idempotencyKey{userID: rec.UserID, key: rec.Key}
+	stored, ok := s.idempotency[k]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.StatusCode != 0 {
+		return nil
+	}
+	stored.StatusCode, stored.Body = rec.StatusCode, rec.Body
+	s.idempotency[k] = stored
+	return nil
+}
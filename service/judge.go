@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// ErrUnsupportedLanguage is returned when a submission uses a language the judge cannot run.
+var ErrUnsupportedLanguage = fmt.Errorf("unsupported language: %w", ErrValidation)
+
+// ErrNoTestCases is returned when a mission has no test cases to judge a
+// submission against.
+var ErrNoTestCases = fmt.Errorf("mission has no test cases: %w", ErrValidation)
+
+// Judge runs a submission against the test cases of its mission.
+// A returned error means the judge itself failed; a failing solution is
+// reported through JudgeResult.Verdict.
+type Judge interface {
+	Judge(ctx context.Context, sub models.Submission, cases []models.TestCase) (JudgeResult, error)
+	// Supports reports whether the judge can run solutions in language.
+	Supports(language string) bool
+}
+
+type JudgeResult struct {
+	Verdict models.Verdict
+	Detail  string
+}
+
+// Language describes how LocalJudge builds and runs a source file.
+type Language struct {
+	// File is the name the source code is written to.
+	File string
+	// Compile is an optional build command run once before the tests.
+	Compile []string
+	// Run starts the solution; it reads the test input from stdin.
+	Run []string
+}
+
+// LocalJudge runs solutions as local processes. Every test run is limited
+// by TimeLimit (wall clock and RLIMIT_CPU), by MemoryLimitMB through
+// RLIMIT_AS and by MaxProcesses through RLIMIT_NPROC. The compile step,
+// which runs untrusted source through the compiler, is limited alike by
+// CompileTimeout, CompileMemoryLimitMB and MaxProcesses. Runs get their
+// own process group, which is killed when they end, so that children
+// cannot outlive the time limit.
+type LocalJudge struct {
+	Languages     map[string]Language
+	TimeLimit     time.Duration
+	MemoryLimitMB int
+	// MaxProcesses limits the processes of the service's user while a
+	// solution runs; run the service as a dedicated user for it to be
+	// meaningful. It is not enforced for root.
+	MaxProcesses   int
+	CompileTimeout time.Duration
+	// CompileMemoryLimitMB is the RLIMIT_AS of the compiler, which needs
+	// more address space than the solutions it builds.
+	CompileMemoryLimitMB int
+	// MaxOutputBytes caps the captured stdout/stderr of a single run.
+	MaxOutputBytes int
+}
+
+var _ Judge = (*LocalJudge)(nil)
+
+func NewLocalJudge(timeLimit time.Duration, memoryLimitMB int) *LocalJudge {
+	return &LocalJudge{
+		Languages: map[string]Language{
+			"python": {File: "main.py", Run: []string{"python3", "main.py"}},
+			"go":     {File: "main.go", Compile: []string{"go", "build", "-o", "main", "main.go"}, Run: []string{"./main"}},
+		},
+		TimeLimit:            timeLimit,
+		MemoryLimitMB:        memoryLimitMB,
+		MaxProcesses:         256,
+		CompileTimeout:       30 * time.Second,
+		CompileMemoryLimitMB: 2048,
+		MaxOutputBytes:       1 << 20,
+	}
+}
+
+func (j *LocalJudge) Supports(language string) bool {
+	_, ok := j.Languages[language]
+	return ok
+}
+
+func (j *LocalJudge) Judge(ctx context.Context, sub models.Submission, cases []models.TestCase) (JudgeResult, error) {
+	lang, ok := j.Languages[sub.Language]
+	if !ok {
+		return JudgeResult{}, fmt.Errorf("%w: %q", ErrUnsupportedLanguage, sub.Language)
+	}
+	if len(cases) == 0 {
+		return JudgeResult{}, ErrNoTestCases
+	}
+
+	dir, err := os.MkdirTemp("", "judge-*")
+	if err != nil {
+		return JudgeResult{}, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, lang.File), []byte(sub.Source), 0o600); err != nil {
+		return JudgeResult{}, err
+	}
+
+	if len(lang.Compile) > 0 {
+		compileCtx, cancel := context.WithTimeout(ctx, j.CompileTimeout)
+		out, err := j.exec(compileCtx, dir, lang.Compile, "", j.compileLimits())
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return JudgeResult{}, ctx.Err()
+			}
+			return JudgeResult{Verdict: models.VerdictCompilationError, Detail: out.stderr}, nil
+		}
+	}
+
+	for i, tc := range cases {
+		runCtx, cancel := context.WithTimeout(ctx, j.TimeLimit)
+		out, err := j.exec(runCtx, dir, lang.Run, tc.Input, j.runLimits())
+		timedOut := errors.Is(runCtx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if ctx.Err() != nil {
+			return JudgeResult{}, ctx.Err()
+		}
+		if timedOut || cpuLimitExceeded(err) {
+			return JudgeResult{Verdict: models.VerdictTimeLimitExceeded, Detail: testDetail(i)}, nil
+		}
+		if err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				return JudgeResult{}, err
+			}
+			return JudgeResult{Verdict: models.VerdictRuntimeError, Detail: testDetail(i) + ": " + out.stderr}, nil
+		}
+		if normalizeOutput(out.stdout) != normalizeOutput(tc.ExpectedOutput) {
+			return JudgeResult{Verdict: models.VerdictWrongAnswer, Detail: testDetail(i)}, nil
+		}
+	}
+
+	return JudgeResult{Verdict: models.VerdictAccepted}, nil
+}
+
+type runOutput struct {
+	stdout string
+	stderr string
+}
+
+// cpuLimitExceeded reports whether err is the exit of a process killed by
+// SIGXCPU for using up the soft RLIMIT_CPU. Other kills, such as SIGKILL
+// from the OOM killer or the process itself, are runtime errors.
+func cpuLimitExceeded(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled() && status.Signal() == syscall.SIGXCPU
+}
+
+// rlimits are the resource limits a process is started with.
+type rlimits struct {
+	memoryMB  int
+	cpu       time.Duration
+	processes int
+}
+
+func (j *LocalJudge) runLimits() rlimits {
+	return rlimits{memoryMB: j.MemoryLimitMB, cpu: j.TimeLimit, processes: j.MaxProcesses}
+}
+
+func (j *LocalJudge) compileLimits() rlimits {
+	return rlimits{memoryMB: j.CompileMemoryLimitMB, cpu: j.CompileTimeout, processes: j.MaxProcesses}
+}
+
+// exec runs args in dir in a new process group, which is killed on
+// cancellation and once the process has exited. The process is started
+// through sh so that ulimit can apply the memory, CPU and process rlimits
+// before exec. The soft CPU limit, which raises SIGXCPU, is a second
+// below the hard one, which kills.
+func (j *LocalJudge) exec(ctx context.Context, dir string, args []string, stdin string, limits rlimits) (runOutput, error) {
+	cpuSeconds := int(limits.cpu/time.Second) + 1
+	script := "ulimit -v " + strconv.Itoa(limits.memoryMB*1024) +
+		" && ulimit -S -t " + strconv.Itoa(cpuSeconds) + " && ulimit -H -t " + strconv.Itoa(cpuSeconds+1)
+	if limits.processes > 0 {
+		// bash calls the process limit -u, dash -p.
+		n := strconv.Itoa(limits.processes)
+		script += " && { ulimit -u " + n + " 2>/dev/null || ulimit -p " + n + "; }"
+	}
+	args = append([]string{"sh", "-c", script + ` && exec "$@"`, "judge"}, args...)
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir, "GOCACHE=" + filepath.Join(dir, ".cache")}
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return killGroup(cmd.Process) }
+
+	// The output goes through pipes of our own rather than ones set up by
+	// exec, so that Wait returns as soon as the process exits even if a
+	// child still holds them open.
+	stdout := &limitedBuffer{max: j.MaxOutputBytes}
+	stderr := &limitedBuffer{max: j.MaxOutputBytes}
+	var readers []*os.File
+	var copies sync.WaitGroup
+	for _, buf := range []*limitedBuffer{stdout, stderr} {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return runOutput{}, err
+		}
+		defer r.Close()
+		defer w.Close()
+		if buf == stdout {
+			cmd.Stdout = w
+		} else {
+			cmd.Stderr = w
+		}
+		readers = append(readers, r)
+		copies.Add(1)
+		go func() {
+			defer copies.Done()
+			_, _ = io.Copy(buf, r)
+		}()
+	}
+
+	err := cmd.Start()
+	// Only the process keeps the write ends open now.
+	_ = cmd.Stdout.(*os.File).Close()
+	_ = cmd.Stderr.(*os.File).Close()
+	if err != nil {
+		copies.Wait()
+		return runOutput{}, err
+	}
+	err = cmd.Wait()
+	// Children left behind keep running in the group until killed here.
+	_ = killGroup(cmd.Process)
+	// A child that left the group could hold the pipes open for good.
+	timer := time.AfterFunc(time.Second, func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	})
+	copies.Wait()
+	timer.Stop()
+	return runOutput{stdout: stdout.String(), stderr: stderr.String()}, err
+}
+
+func killGroup(p *os.Process) error {
+	return syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
+
+func testDetail(i int) string {
+	return "test " + strconv.Itoa(i+1)
+}
+
+// normalizeOutput ignores trailing whitespace on every line and trailing blank lines.
+func normalizeOutput(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// limitedBuffer keeps at most max bytes and silently drops the rest.
+type limitedBuffer struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
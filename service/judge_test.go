@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func newShellJudge() *service.LocalJudge {
+	judge := service.NewLocalJudge(500*time.Millisecond, 256)
+	judge.Languages = map[string]service.Language{
+		"sh": {File: "main.sh", Run: []string{"sh", "main.sh"}},
+	}
+	return judge
+}
+
+func TestLocalJudgeVerdicts(t *testing.T) {
+	cases := []models.TestCase{
+		{Input: "2\n", ExpectedOutput: "4\n"},
+		{Input: "3\n", ExpectedOutput: "9\n"},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   models.Verdict
+	}{
+		{"accepted", "read n; echo $((n * n))", models.VerdictAccepted},
+		{"wrong answer", "read n; echo $((n + n))", models.VerdictWrongAnswer},
+		{"time limit", "sleep 5", models.VerdictTimeLimitExceeded},
+		{"runtime error", "exit 3", models.VerdictRuntimeError},
+		// RLIMIT_CPU sends SIGXCPU at the soft limit.
+		{"cpu limit", "kill -XCPU $$", models.VerdictTimeLimitExceeded},
+		// Other kills, e.g. by the OOM killer, are no time limit.
+		{"killed", "kill -KILL $$", models.VerdictRuntimeError},
+		// The background child keeps stdout open; it is killed with the
+		// process group instead of failing the run.
+		{"leftover child", "sleep 30 & read n; echo $((n * n))", models.VerdictAccepted},
+	}
+
+	judge := newShellJudge()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := models.Submission{Language: "sh", Source: tt.source}
+			result, err := judge.Judge(context.Background(), sub, cases)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Verdict != tt.want {
+				t.Errorf("expected verdict %s, got %s (%s)", tt.want, result.Verdict, result.Detail)
+			}
+		})
+	}
+}
+
+func TestLocalJudgeLimitsCompilation(t *testing.T) {
+	judge := newShellJudge()
+	judge.CompileMemoryLimitMB = 512
+	judge.Languages["sh"] = service.Language{
+		File:    "main.sh",
+		Compile: []string{"sh", "-c", `[ "$(ulimit -v)" = 524288 ]`},
+		Run:     []string{"sh", "main.sh"},
+	}
+	sub := models.Submission{Language: "sh", Source: "read n; echo $((n * n))"}
+	result, err := judge.Judge(context.Background(), sub, []models.TestCase{{Input: "2\n", ExpectedOutput: "4\n"}})
+	if err != nil || result.Verdict != models.VerdictAccepted {
+		t.Errorf("expected the compiler to run with its memory limit, got %+v (%v)", result, err)
+	}
+}
+
+func TestProcessSubmissionAwardsPointsOnce(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: newShellJudge()}
+	ctx := context.Background()
+
+	sub := models.Submission{UserID: 1, MissionID: 1, Language: "sh", Source: "echo 'Hello, World!'"}
+	for i := 0; i < 2; i++ {
+		queued, err := svc.SubmitSolution(ctx, sub)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if queued.Status != models.SubmissionQueued {
+			t.Fatalf("expected queued submission, got %s", queued.Status)
+		}
+
+		claimed, err := store.ClaimSubmission(ctx, time.Minute)
+		if err != nil {
+			t.Fatalf("could not claim submission: %v", err)
+		}
+		judged, err := svc.ProcessSubmission(ctx, claimed)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if judged.Verdict != models.VerdictAccepted {
+			t.Fatalf("expected accepted, got %s (%s)", judged.Verdict, judged.Detail)
+		}
+	}
+
+	profile, err := svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.TotalPoints != 100 {
+		t.Errorf("expected 100 points after two accepted submissions, got %d", profile.TotalPoints)
+	}
+}
+
+func TestSubmitSolutionRejectsUnsupportedLanguage(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: newShellJudge()}
+
+	sub := models.Submission{UserID: 1, MissionID: 1, Language: "cobol", Source: "DISPLAY 'HI'."}
+	if _, err := svc.SubmitSolution(context.Background(), sub); !errors.Is(err, service.ErrUnsupportedLanguage) {
+		t.Errorf("expected ErrUnsupportedLanguage, got %v", err)
+	}
+}
+
+func TestJudgeRequiresTestCases(t *testing.T) {
+	judge := newShellJudge()
+	sub := models.Submission{Language: "sh", Source: "true"}
+	if _, err := judge.Judge(context.Background(), sub, nil); !errors.Is(err, service.ErrNoTestCases) {
+		t.Errorf("expected ErrNoTestCases, got %v", err)
+	}
+
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: judge}
+	m, err := store.AddMission(context.Background(), models.Mission{Title: "Untested", Points: 10})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	sub = models.Submission{UserID: 1, MissionID: m.ID, Language: "sh", Source: "true"}
+	if _, err := svc.SubmitSolution(context.Background(), sub); !errors.Is(err, service.ErrNoTestCases) {
+		t.Errorf("expected ErrNoTestCases, got %v", err)
+	}
+}
+
+func TestGetSubmissionIsLimitedToItsUser(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: newShellJudge()}
+	as := func(userID int, role models.Role) context.Context {
+		return service.WithPrincipal(context.Background(), models.Principal{UserID: userID, Role: role})
+	}
+
+	sub, err := svc.SubmitSolution(as(1, models.RolePlayer), models.Submission{UserID: 1, MissionID: 1, Language: "sh", Source: "true"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.GetSubmission(as(1, models.RolePlayer), sub.ID); err != nil {
+		t.Errorf("owner: unexpected error %v", err)
+	}
+	if _, err := svc.GetSubmission(as(2, models.RoleAdmin), sub.ID); err != nil {
+		t.Errorf("admin: unexpected error %v", err)
+	}
+	if _, err := svc.GetSubmission(as(2, models.RoleAuthor), sub.ID); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("other user: expected ErrForbidden, got %v", err)
+	}
+	if _, err := svc.GetSubmission(context.Background(), sub.ID); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("anonymous: expected ErrForbidden, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// WeekStart returns the start of the week containing t: Monday 00:00 UTC.
+// Weekly leaderboards count completions since then.
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// LeaderboardCursor is the decoded position after the last entry of a
+// page. Leaderboards are ordered by points, highest first, then user ID.
+type LeaderboardCursor struct {
+	Points int `json:"p"`
+	UserID int `json:"id"`
+}
+
+// EncodeLeaderboardCursor returns the cursor pointing after e.
+func EncodeLeaderboardCursor(e models.LeaderboardEntry) string {
+	b, _ := json.Marshal(LeaderboardCursor{Points: e.Points, UserID: e.UserID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeLeaderboardCursor parses a cursor produced by EncodeLeaderboardCursor.
+func DecodeLeaderboardCursor(s string) (LeaderboardCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return LeaderboardCursor{}, ErrInvalidCursor
+	}
+	var c LeaderboardCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return LeaderboardCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Follows reports whether e comes after the cursor in leaderboard order.
+func (c LeaderboardCursor) Follows(e models.LeaderboardEntry) bool {
+	return cmp.Or(cmp.Compare(c.Points, e.Points), cmp.Compare(e.UserID, c.UserID)) > 0
+}
+
+// entriesAfter returns up to limit of the ranked entries that follow after,
+// all of them from the start when after is nil.
+func entriesAfter(entries []models.LeaderboardEntry, after *LeaderboardCursor, limit int) []models.LeaderboardEntry {
+	start := 0
+	if after != nil {
+		start = slices.IndexFunc(entries, after.Follows)
+		if start < 0 {
+			start = len(entries)
+		}
+	}
+	end := min(start+limit, len(entries))
+	return slices.Clone(entries[start:end])
+}
+
+// Leaderboard returns a page of the leaderboard selected by q. Its After
+// and Limit are taken from cursor, the NextCursor of the previous page,
+// and limit.
+func (s *MissionService) Leaderboard(ctx context.Context, q LeaderboardQuery, cursor string, limit int) (models.LeaderboardPage, error) {
+	q = s.resolvePeriod(q)
+	return leaderboardPage(cursor, limit, func(after *LeaderboardCursor, limit int) ([]models.LeaderboardEntry, error) {
+		q.After, q.Limit = after, limit
+		return s.Leaderboards.Leaderboard(ctx, q)
+	})
+}
+
+// resolvePeriod sets the Since of a weekly query from the service's clock.
+func (s *MissionService) resolvePeriod(q LeaderboardQuery) LeaderboardQuery {
+	if q.Weekly {
+		q.Since = WeekStart(s.now())
+	}
+	return q
+}
+
+// leaderboardPage fetches the page at cursor with up to limit entries. It
+// asks fetch for one entry more to tell whether there is a next page.
+func leaderboardPage(cursor string, limit int, fetch func(after *LeaderboardCursor, limit int) ([]models.LeaderboardEntry, error)) (models.LeaderboardPage, error) {
+	var after *LeaderboardCursor
+	if cursor != "" {
+		c, err := DecodeLeaderboardCursor(cursor)
+		if err != nil {
+			return models.LeaderboardPage{}, err
+		}
+		after = &c
+	}
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+
+	entries, err := fetch(after, limit+1)
+	if err != nil {
+		return models.LeaderboardPage{}, err
+	}
+	page := models.LeaderboardPage{Items: entries}
+	if len(entries) > limit {
+		page.Items = entries[:limit]
+		page.NextCursor = EncodeLeaderboardCursor(page.Items[limit-1])
+	}
+	return page, nil
+}
+
+// UserRank returns the standing of the user on the leaderboard selected by
+// q. Users without points get an entry with rank 0.
+func (s *MissionService) UserRank(ctx context.Context, userID int, q LeaderboardQuery) (models.LeaderboardEntry, error) {
+	u, err := s.Users.GetUser(ctx, userID)
+	if err != nil {
+		return models.LeaderboardEntry{}, err
+	}
+	e, err := s.Leaderboards.UserRank(ctx, userID, s.resolvePeriod(q))
+	if errors.Is(err, ErrNotFound) {
+		return models.LeaderboardEntry{UserID: u.ID, Username: u.Username}, nil
+	}
+	return e, err
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// LeaderboardQuery selects the completions a leaderboard is computed from.
+type LeaderboardQuery struct {
+	// Since counts only completions at or after it; all of them when zero.
+	Since time.Time
+	// Until counts only completions before it; all of them when zero.
+	Until time.Time
+	// Category counts only missions of the category when set.
+	Category string
+	// Weekly counts only completions since the start of the current week,
+	// by the clock of the MissionService, which sets Since from it.
+	Weekly bool
+	// After starts the page after the cursor; at the top when nil.
+	After *LeaderboardCursor
+	Limit int
+}
+
+// LeaderboardStore ranks users by the points of their completed missions.
+// Missions deleted after completion still count, as on profiles.
+type LeaderboardStore interface {
+	// Leaderboard returns up to Limit entries following After.
+	Leaderboard(ctx context.Context, q LeaderboardQuery) ([]models.LeaderboardEntry, error)
+	// UserRank returns the entry of the user, or ErrNotFound when the user
+	// has no points on the leaderboard.
+	UserRank(ctx context.Context, userID int, q LeaderboardQuery) (models.LeaderboardEntry, error)
+	// UserPoints returns the user's points on the leaderboard, 0 when they
+	// have none. Unlike UserRank it does not rank the other users.
+	UserPoints(ctx context.Context, userID int, q LeaderboardQuery) (int, error)
+}
+
+var _ LeaderboardStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) Leaderboard(ctx context.Context, q LeaderboardQuery) ([]models.LeaderboardEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return entriesAfter(s.rankedEntries(q), q.After, q.Limit), nil
+}
+
+func (s *InMemoryStore) UserRank(ctx context.Context, userID int, q LeaderboardQuery) (models.LeaderboardEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.rankedEntries(q) {
+		if e.UserID == userID {
+			return e, nil
+		}
+	}
+	return models.LeaderboardEntry{}, ErrNotFound
+}
+
+func (s *InMemoryStore) UserPoints(ctx context.Context, userID int, q LeaderboardQuery) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	points := 0
+	for _, c := range s.completions {
+		if m, ok := s.scoredMission(c, q); ok && c.UserID == userID {
+			points += m.Points
+		}
+	}
+	return points, nil
+}
+
+// scoredMission returns the mission of c if c counts on the leaderboard
+// selected by q. The caller must hold s.mu.
+func (s *InMemoryStore) scoredMission(c models.Completion, q LeaderboardQuery) (models.Mission, bool) {
+	if (!q.Since.IsZero() && c.CompletedAt.Before(q.Since)) || (!q.Until.IsZero() && !c.CompletedAt.Before(q.Until)) {
+		return models.Mission{}, false
+	}
+	i := s.findAnyMission(c.MissionID)
+	if i < 0 || (q.Category != "" && s.missions[i].Category != q.Category) {
+		return models.Mission{}, false
+	}
+	return s.missions[i], true
+}
+
+// rankedEntries computes the whole leaderboard like the window functions of
+// the Postgres store. The caller must hold s.mu.
+func (s *InMemoryStore) rankedEntries(q LeaderboardQuery) []models.LeaderboardEntry {
+	byUser := make(map[int]*models.LeaderboardEntry)
+	for _, c := range s.completions {
+		m, ok := s.scoredMission(c, q)
+		if !ok {
+			continue
+		}
+		e, ok := byUser[c.UserID]
+		if !ok {
+			e = &models.LeaderboardEntry{UserID: c.UserID}
+			if u := s.findUser(c.UserID); u >= 0 {
+				e.Username = s.users[u].Username
+			}
+			byUser[c.UserID] = e
+		}
+		e.Points += m.Points
+		e.Completed++
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(byUser))
+	for _, e := range byUser {
+		entries = append(entries, *e)
+	}
+	slices.SortFunc(entries, func(a, b models.LeaderboardEntry) int {
+		return cmp.Or(cmp.Compare(b.Points, a.Points), cmp.Compare(a.UserID, b.UserID))
+	})
+	for i := range entries {
+		if i > 0 && entries[i].Points == entries[i-1].Points {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+	return entries
+}
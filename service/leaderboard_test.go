@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestWeekStart(t *testing.T) {
+	sunday := time.Date(2025, 7, 13, 23, 30, 0, 0, time.FixedZone("UTC+3", 3*3600))
+	if got, want := service.WeekStart(sunday), time.Date(2025, 7, 7, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	monday := time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)
+	if got := service.WeekStart(monday); !got.Equal(monday) {
+		t.Errorf("expected %v, got %v", monday, got)
+	}
+}
+
+func TestWeeklyLeaderboardFollowsTheClock(t *testing.T) {
+	store := service.NewInMemoryStore()
+	clock := &fakeClock{now: time.Date(2025, 7, 13, 23, 0, 0, 0, time.UTC)}
+	store.Clock = clock
+	svc := &service.MissionService{Store: store, Users: store, Leaderboards: store, Clock: clock}
+	ctx := context.Background()
+	if err := store.AddCompletion(ctx, 1, 1); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+
+	weekly := service.LeaderboardQuery{Weekly: true}
+	if page, err := svc.Leaderboard(ctx, weekly, "", 0); err != nil || len(page.Items) != 1 {
+		t.Errorf("expected the completion on Sunday's weekly leaderboard, got %+v (%v)", page.Items, err)
+	}
+	clock.now = clock.now.Add(2 * time.Hour)
+	if page, err := svc.Leaderboard(ctx, weekly, "", 0); err != nil || len(page.Items) != 0 {
+		t.Errorf("expected an empty leaderboard on Monday, got %+v (%v)", page.Items, err)
+	}
+	if rank, err := svc.UserRank(ctx, 1, weekly); err != nil || rank.Rank != 0 {
+		t.Errorf("expected no weekly rank on Monday, got %+v (%v)", rank, err)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Leaderboards: store}
+	ctx := context.Background()
+
+	loops, err := store.AddMission(ctx, models.Mission{Title: "Loops", Category: "loops", Points: 50})
+	if err != nil {
+		t.Fatalf("could not add mission: %v", err)
+	}
+	var users []int
+	for _, name := range []string{"alice", "bob", "carol"} {
+		u, err := store.AddUser(ctx, models.User{Username: name})
+		if err != nil {
+			t.Fatalf("could not add user: %v", err)
+		}
+		users = append(users, u.ID)
+	}
+	alice, bob, carol := users[0], users[1], users[2]
+	for _, c := range []struct{ user, mission int }{
+		{alice, 1}, {alice, 2}, {bob, 2}, {carol, 2}, {carol, loops.ID},
+	} {
+		if err := store.AddCompletion(ctx, c.user, c.mission); err != nil {
+			t.Fatalf("could not add completion: %v", err)
+		}
+	}
+
+	page, err := svc.Leaderboard(ctx, service.LeaderboardQuery{}, "", 2)
+	if err != nil {
+		t.Fatalf("could not get leaderboard: %v", err)
+	}
+	if len(page.Items) != 2 || page.NextCursor == "" {
+		t.Fatalf("expected a full first page, got %+v", page)
+	}
+	next, err := svc.Leaderboard(ctx, service.LeaderboardQuery{}, page.NextCursor, 2)
+	if err != nil {
+		t.Fatalf("could not get second page: %v", err)
+	}
+	entries := append(page.Items, next.Items...)
+	want := []models.LeaderboardEntry{
+		{Rank: 1, UserID: alice, Username: "alice", Points: 300, Completed: 2},
+		{Rank: 2, UserID: carol, Username: "carol", Points: 250, Completed: 2},
+		{Rank: 3, UserID: bob, Username: "bob", Points: 200, Completed: 1},
+	}
+	if len(entries) != 3 || next.NextCursor != "" {
+		t.Fatalf("expected three entries on two pages, got %+v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("entry %d: expected %+v, got %+v", i, want[i], entries[i])
+		}
+	}
+
+	category, err := svc.Leaderboard(ctx, service.LeaderboardQuery{Category: "basics"}, "", 0)
+	if err != nil || len(category.Items) != 3 || category.Items[1].Rank != 2 || category.Items[2].Rank != 2 {
+		t.Errorf("expected bob and carol to share rank 2 in basics, got %+v (%v)", category.Items, err)
+	}
+	future, err := svc.Leaderboard(ctx, service.LeaderboardQuery{Since: time.Now().Add(time.Hour)}, "", 0)
+	if err != nil || len(future.Items) != 0 {
+		t.Errorf("expected no completions since the future, got %+v (%v)", future.Items, err)
+	}
+	if _, err := svc.Leaderboard(ctx, service.LeaderboardQuery{}, "not a cursor", 0); !errors.Is(err, service.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+
+	rank, err := svc.UserRank(ctx, carol, service.LeaderboardQuery{Category: "loops"})
+	if err != nil || rank.Rank != 1 || rank.Points != 50 {
+		t.Errorf("unexpected loops rank %+v (%v)", rank, err)
+	}
+	rank, err = svc.UserRank(ctx, 1, service.LeaderboardQuery{})
+	if err != nil || rank.Rank != 0 || rank.Username != "demo" {
+		t.Errorf("expected demo to be unranked, got %+v (%v)", rank, err)
+	}
+	if _, err := svc.UserRank(ctx, 999, service.LeaderboardQuery{}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown user, got %v", err)
+	}
+
+	// A user overtaking the first page does not repeat its last entry.
+	dave, err := store.AddUser(ctx, models.User{Username: "dave"})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	for _, mission := range []int{1, 2, 3} {
+		if err := store.AddCompletion(ctx, dave.ID, mission); err != nil {
+			t.Fatalf("could not add completion: %v", err)
+		}
+	}
+	next, err = svc.Leaderboard(ctx, service.LeaderboardQuery{}, page.NextCursor, 2)
+	if err != nil || len(next.Items) != 1 || next.Items[0].UserID != bob || next.Items[0].Rank != 4 {
+		t.Errorf("expected the second page to continue after carol, got %+v (%v)", next.Items, err)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit allows bursts of up to Requests and refills them evenly over Per.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseRateLimit parses limits written as "<requests>/<duration>", e.g. "10/1m".
+func ParseRateLimit(s string) (RateLimit, error) {
+	n, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("rate limit %q: want <requests>/<duration>", s)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(n))
+	if err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: requests must be a positive number", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(per))
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q: invalid duration", s)
+	}
+	return RateLimit{Requests: requests, Per: d}, nil
+}
+
+// Rate returns the refill rate in tokens per second.
+func (l RateLimit) Rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// LimitDecision is the outcome of taking a token from a bucket.
+type LimitDecision struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token when the request was
+	// rejected.
+	RetryAfter time.Duration
+}
+
+// NewLimitDecision describes a bucket left with tokens after a request
+// that was allowed or not.
+func NewLimitDecision(limit RateLimit, tokens float64, allowed bool) LimitDecision {
+	rate := limit.Rate()
+	d := LimitDecision{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(limit.Requests) - tokens) / rate),
+	}
+	if !allowed {
+		d.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return d
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// LimiterStore keeps token buckets. Stores shared by several instances of
+// the service make limits hold across all of them. Limiting fails open: a
+// request whose Take returns an error is let through, so an unavailable
+// store, such as Postgres during an outage, disables rate limiting rather
+// than the API.
+type LimiterStore interface {
+	// Take refills the bucket key according to limit and removes one token
+	// if there is one. New buckets start full.
+	Take(ctx context.Context, key string, limit RateLimit) (LimitDecision, error)
+	// EvictIdle removes buckets unused for longer than idle, measured by
+	// the store's own clock.
+	EvictIdle(ctx context.Context, idle time.Duration) error
+}
+
+// MemoryLimiterStore keeps token buckets in process memory, so every
+// instance of the service has its own quota. Its Take never fails.
+type MemoryLimiterStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+var _ LimiterStore = (*MemoryLimiterStore)(nil)
+
+func NewMemoryLimiterStore() *MemoryLimiterStore {
+	return &MemoryLimiterStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *MemoryLimiterStore) Take(ctx context.Context, key string, limit RateLimit) (LimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(limit.Requests)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*limit.Rate())
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return NewLimitDecision(limit, b.tokens, allowed), nil
+}
+
+func (s *MemoryLimiterStore) EvictIdle(ctx context.Context, idle time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := time.Now().Add(-idle)
+	for key, b := range s.buckets {
+		if b.last.Before(before) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
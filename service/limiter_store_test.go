@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/pseudoerr/mission-service/repository"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	if l, err := service.ParseRateLimit("10/1m"); err != nil || l != (service.RateLimit{Requests: 10, Per: time.Minute}) {
+		t.Errorf("unexpected limit %+v (%v)", l, err)
+	}
+	for _, s := range []string{"", "10", "0/1m", "10/x", "10/-1s"} {
+		if _, err := service.ParseRateLimit(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+// TestLimiterStore runs against the memory store, and against Postgres when
+// TEST_DATABASE_URL is set.
+func TestLimiterStore(t *testing.T) {
+	stores := map[string]service.LimiterStore{"memory": service.NewMemoryLimiterStore()}
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		db, err := sql.Open("postgres", url)
+		if err != nil {
+			t.Fatalf("could not open db: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		stores["postgres"] = repository.NewPostgresRepository(db)
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			prefix := "test:" + strconv.FormatInt(time.Now().UnixNano(), 36) + ":"
+			limit := service.RateLimit{Requests: 2, Per: time.Hour}
+
+			for i, remaining := range []int{1, 0} {
+				d, err := store.Take(ctx, prefix+"a", limit)
+				if err != nil || !d.Allowed || d.Remaining != remaining {
+					t.Fatalf("take %d: got %+v (%v)", i+1, d, err)
+				}
+			}
+			d, err := store.Take(ctx, prefix+"a", limit)
+			if err != nil || d.Allowed || d.RetryAfter < 29*time.Minute || d.RetryAfter > 30*time.Minute {
+				t.Errorf("exhausted bucket: got %+v (%v)", d, err)
+			}
+			if d, err := store.Take(ctx, prefix+"b", limit); err != nil || !d.Allowed {
+				t.Errorf("other key: got %+v (%v)", d, err)
+			}
+
+			// Concurrent takes must not hand out more tokens than the bucket holds.
+			var wg sync.WaitGroup
+			var allowed atomic.Int32
+			for range 10 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					d, err := store.Take(ctx, prefix+"c", service.RateLimit{Requests: 5, Per: time.Hour})
+					if err != nil {
+						t.Errorf("concurrent take: %v", err)
+					}
+					if d.Allowed {
+						allowed.Add(1)
+					}
+				}()
+			}
+			wg.Wait()
+			if allowed.Load() != 5 {
+				t.Errorf("expected 5 concurrent takes to pass, got %d", allowed.Load())
+			}
+
+			if err := store.EvictIdle(ctx, 0); err != nil {
+				t.Fatalf("could not evict: %v", err)
+			}
+			if d, err := store.Take(ctx, prefix+"a", limit); err != nil || !d.Allowed || d.Remaining != 1 {
+				t.Errorf("evicted bucket should start full, got %+v (%v)", d, err)
+			}
+		})
+	}
+}
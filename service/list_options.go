@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// Supported values of ListOptions.Sort. A leading "-" means descending order;
+// the empty value sorts by ID.
+const (
+	SortPoints        = "points"
+	SortPointsDesc    = "-points"
+	SortCreatedAt     = "created_at"
+	SortCreatedAtDesc = "-created_at"
+)
+
+var (
+	ErrInvalidSort   = fmt.Errorf("invalid sort: %w", ErrValidation)
+	ErrInvalidCursor = fmt.Errorf("invalid cursor: %w", ErrValidation)
+)
+
+// ListOptions filters, sorts and paginates MissionStore.ListMissions.
+type ListOptions struct {
+	Difficulty models.Difficulty
+	Tag        string
+	Category   string
+	MinPoints  *int
+	MaxPoints  *int
+	Sort       string
+	// Cursor is the opaque NextCursor of the previous page.
+	Cursor string
+	Limit  int
+	// Deleted lists the trash: only soft-deleted missions instead of live ones.
+	Deleted bool
+}
+
+// Cursor is the decoded position after the last mission of a page.
+type Cursor struct {
+	Sort      string    `json:"s,omitempty"`
+	ID        int       `json:"id"`
+	Points    int       `json:"p,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+}
+
+// SortField returns the column name and direction of sort. It returns an
+// empty field for the default ID order.
+func SortField(sort string) (field string, desc bool, err error) {
+	switch sort {
+	case "":
+		return "", false, nil
+	case SortPoints, SortCreatedAt:
+		return sort, false, nil
+	case SortPointsDesc, SortCreatedAtDesc:
+		return sort[1:], true, nil
+	default:
+		return "", false, ErrInvalidSort
+	}
+}
+
+// EncodeCursor returns the cursor pointing after m in a listing sorted by sort.
+func EncodeCursor(sort string, m models.Mission) string {
+	c := Cursor{Sort: sort, ID: m.ID}
+	switch sort {
+	case SortPoints, SortPointsDesc:
+		c.Points = m.Points
+	case SortCreatedAt, SortCreatedAtDesc:
+		c.CreatedAt = m.CreatedAt
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor produced by EncodeCursor for the same sort order.
+func DecodeCursor(sort, s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// normalize validates the options and applies the default page size.
+func (o ListOptions) normalize() (ListOptions, error) {
+	if _, _, err := SortField(o.Sort); err != nil {
+		return o, err
+	}
+	if o.Cursor != "" {
+		if _, err := DecodeCursor(o.Sort, o.Cursor); err != nil {
+			return o, err
+		}
+	}
+	if o.Limit <= 0 {
+		o.Limit = DefaultListLimit
+	}
+	if o.Limit > MaxListLimit {
+		o.Limit = MaxListLimit
+	}
+	return o, nil
+}
@@ -1,45 +1,224 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/pseudoerr/mission-service/models"
 	"log/slog"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
 type MissionStore interface {
-	ListMissions(ctx context.Context) ([]models.Mission, error)
+	ListMissions(ctx context.Context, opts ListOptions) (models.MissionPage, error)
+	SearchMissions(ctx context.Context, query string, limit int) ([]models.Mission, error)
 	AddMission(ctx context.Context, m models.Mission) (models.Mission, error)
 	GetByID(ctx context.Context, id int) (models.Mission, error)
+	// GetDeleted returns a mission from the trash; live missions are ErrNotFound.
+	GetDeleted(ctx context.Context, id int) (models.Mission, error)
+	// UpdateMission replaces the editable fields of the mission; the author
+	// is kept.
 	UpdateMission(ctx context.Context, m models.Mission) (models.Mission, error)
-	DeleteMission(ctx context.Context, id int) error
+	// DeleteMission moves the mission to the trash. A non-zero version must
+	// match the stored version, otherwise ErrVersionMismatch is returned.
+	// Deleted missions are hidden from every other method except
+	// ListMissions with ListOptions.Deleted.
+	DeleteMission(ctx context.Context, id, version int) error
+	// RestoreMission takes a mission out of the trash. It returns ErrNotFound
+	// unless the mission is deleted.
+	RestoreMission(ctx context.Context, id int) (models.Mission, error)
+	// PurgeMission permanently removes the mission, deleted or not, together
+	// with its completions, test cases and submissions.
+	PurgeMission(ctx context.Context, id int) error
 }
 
 type InMemoryStore struct {
-	mu       sync.Mutex
-	missions []models.Mission
-	nextID   int
+	// Clock stamps completions, which leaderboards and seasons count by;
+	// the real time when nil.
+	Clock Clock
+
+	mu          sync.Mutex
+	missions    []models.Mission
+	nextID      int
+	users       []models.User
+	nextUserID  int
+	completions []models.Completion
+	idempotency map[idempotencyKey]models.IdempotencyRecord
+
+	testCases        map[int][]models.TestCase
+	nextTestCaseID   int
+	submissions      []models.Submission
+	nextSubmissionID int
+
+	refreshTokens      []models.RefreshToken
+	nextRefreshTokenID int
+	apiKeys            []models.APIKey
+	nextAPIKeyID       int
+	achievements       []models.Achievement
+	streaks            map[int]models.Streak
+	seasons            []models.Season
+	nextSeasonID       int
+	standings          map[int][]models.LeaderboardEntry
 }
 
 type MissionService struct {
-	Store  MissionStore
-	Logger *slog.Logger
+	Store       MissionStore
+	Users       UserStore
+	Idempotency IdempotencyStore
+	Submissions SubmissionStore
+	Accounts    AccountStore
+	APIKeys     APIKeyStore
+	// Achievements persists awarded badges. Without it badges are
+	// evaluated on every profile read and event-only badges are never
+	// awarded.
+	Achievements AchievementStore
+	Leaderboards LeaderboardStore
+	// Seasons keeps quarterly seasons; without it profiles show no season
+	// points.
+	Seasons SeasonStore
+	// Streaks tracks daily activity; without it profiles show no streaks.
+	Streaks StreakStore
+	Judge   Judge
+	Logger  *slog.Logger
+	// Clock tells the time for streaks, achievements and seasons; the system clock
+	// when nil.
+	Clock Clock
+	// RefreshTokenTTL is the lifetime of refresh tokens issued by Login
+	// and Refresh; DefaultRefreshTokenTTL when zero.
+	RefreshTokenTTL time.Duration
+	// Rules decide profile levels and badges; DefaultRules when empty.
+	Rules models.GamificationRules
 }
 
+var _ MissionStore = (*InMemoryStore)(nil)
+
 func NewInMemoryStore() *InMemoryStore {
+	now := time.Now()
 	return &InMemoryStore{
 		missions: []models.Mission{
-			{ID: 1, Title: "Hello, World!", Points: 100},
-			{ID: 2, Title: "FizzBuzz", Points: 200},
+			{
+				ID:          1,
+				Title:       "Hello, World!",
+				Description: "Print `Hello, World!` to standard output.",
+				Difficulty:  models.DifficultyEasy,
+				Tags:        []string{"basics", "io"},
+				Category:    "basics",
+				Points:      100,
+				Version:     1,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				PublishedAt: now,
+			},
+			{
+				ID:          2,
+				Title:       "FizzBuzz",
+				Description: "Read `n` and print the numbers from 1 to `n`, replacing multiples of 3 with `Fizz`, multiples of 5 with `Buzz` and multiples of both with `FizzBuzz`.",
+				Difficulty:  models.DifficultyMedium,
+				Tags:        []string{"loops", "math"},
+				Category:    "basics",
+				Points:      200,
+				Version:     1,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				PublishedAt: now,
+			},
 		},
 		nextID: 3,
+		users: []models.User{
+			{ID: 1, Username: "demo", Role: models.RolePlayer, CreatedAt: now},
+		},
+		nextUserID: 2,
+		testCases: map[int][]models.TestCase{
+			1: {{ID: 1, MissionID: 1, Input: "", ExpectedOutput: "Hello, World!\n"}},
+			2: {{ID: 2, MissionID: 2, Input: "5\n", ExpectedOutput: "1\n2\nFizz\n4\nBuzz\n"}},
+		},
+		nextTestCaseID: 3,
 	}
 }
 
-func (s *InMemoryStore) ListMissions(ctx context.Context) ([]models.Mission, error) {
+func (s *InMemoryStore) ListMissions(ctx context.Context, opts ListOptions) (models.MissionPage, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return models.MissionPage{}, err
+	}
+	field, desc, _ := SortField(opts.Sort)
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	return append([]models.Mission{}, s.missions...), nil
+	var missions []models.Mission
+	for _, m := range s.missions {
+		if matchesListOptions(m, opts) {
+			missions = append(missions, m)
+		}
+	}
+	s.mu.Unlock()
+
+	// compare orders missions by the sort field, breaking ties by ID.
+	compare := func(a, b models.Mission) int {
+		c := 0
+		switch field {
+		case SortPoints:
+			c = cmp.Compare(a.Points, b.Points)
+		case SortCreatedAt:
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if desc {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(missions, compare)
+
+	if opts.Cursor != "" {
+		cur, _ := DecodeCursor(opts.Sort, opts.Cursor)
+		last := models.Mission{ID: cur.ID, Points: cur.Points, CreatedAt: cur.CreatedAt}
+		start := len(missions)
+		for i, m := range missions {
+			if compare(m, last) > 0 {
+				start = i
+				break
+			}
+		}
+		missions = missions[start:]
+	}
+
+	page := models.MissionPage{Items: missions}
+	if len(missions) > opts.Limit {
+		page.Items = missions[:opts.Limit]
+		page.NextCursor = EncodeCursor(opts.Sort, page.Items[opts.Limit-1])
+	}
+	if page.Items == nil {
+		page.Items = []models.Mission{}
+	}
+	return page, nil
+}
+
+func matchesListOptions(m models.Mission, opts ListOptions) bool {
+	if (m.DeletedAt != nil) != opts.Deleted {
+		return false
+	}
+	if opts.Difficulty != "" && m.Difficulty != opts.Difficulty {
+		return false
+	}
+	if opts.Tag != "" && !slices.Contains(m.Tags, opts.Tag) {
+		return false
+	}
+	if opts.Category != "" && m.Category != opts.Category {
+		return false
+	}
+	if opts.MinPoints != nil && m.Points < *opts.MinPoints {
+		return false
+	}
+	if opts.MaxPoints != nil && m.Points > *opts.MaxPoints {
+		return false
+	}
+	return true
 }
 
 func (s *InMemoryStore) AddMission(ctx context.Context, m models.Mission) (models.Mission, error) {
@@ -47,46 +226,333 @@ func (s *InMemoryStore) AddMission(ctx context.Context, m models.Mission) (model
 	defer s.mu.Unlock()
 	m.ID = s.nextID
 	s.nextID++
+	NormalizeMission(&m)
+	m.Version = 1
+	m.CreatedAt = time.Now()
+	m.UpdatedAt = m.CreatedAt
+	m.PublishedAt = m.CreatedAt
 	s.missions = append(s.missions, m)
 	return m, nil
 }
 
-func (s *MissionService) GetProfile(ctx context.Context) (models.Profile, error) {
-	missions, err := s.Store.ListMissions(ctx)
+func (s *InMemoryStore) GetByID(ctx context.Context, id int) (models.Mission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findMission(id)
+	if i < 0 {
+		return models.Mission{}, ErrNotFound
+	}
+	return s.missions[i], nil
+}
+
+func (s *InMemoryStore) GetDeleted(ctx context.Context, id int) (models.Mission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findAnyMission(id)
+	if i < 0 || s.missions[i].DeletedAt == nil {
+		return models.Mission{}, ErrNotFound
+	}
+	return s.missions[i], nil
+}
+
+func (s *InMemoryStore) UpdateMission(ctx context.Context, m models.Mission) (models.Mission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findMission(m.ID)
+	if i < 0 {
+		return models.Mission{}, ErrNotFound
+	}
+	stored := s.missions[i]
+	if m.Version != 0 && m.Version != stored.Version {
+		return models.Mission{}, ErrVersionMismatch
+	}
+	NormalizeMission(&m)
+	m.AuthorID = stored.AuthorID
+	m.Version = stored.Version + 1
+	m.CreatedAt = stored.CreatedAt
+	m.UpdatedAt = time.Now()
+	m.PublishedAt = stored.PublishedAt
+	if stored.Archived && !m.Archived {
+		m.PublishedAt = m.UpdatedAt
+	}
+	s.missions[i] = m
+	return m, nil
+}
+
+func (s *InMemoryStore) DeleteMission(ctx context.Context, id, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findMission(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	m := &s.missions[i]
+	if version != 0 && version != m.Version {
+		return ErrVersionMismatch
+	}
+	now := time.Now()
+	m.DeletedAt = &now
+	m.Version++
+	m.UpdatedAt = now
+	return nil
+}
+
+func (s *InMemoryStore) RestoreMission(ctx context.Context, id int) (models.Mission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findAnyMission(id)
+	if i < 0 || s.missions[i].DeletedAt == nil {
+		return models.Mission{}, ErrNotFound
+	}
+	m := &s.missions[i]
+	m.DeletedAt = nil
+	m.Version++
+	m.UpdatedAt = time.Now()
+	m.PublishedAt = m.UpdatedAt
+	return *m, nil
+}
+
+func (s *InMemoryStore) PurgeMission(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findAnyMission(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	s.missions = append(s.missions[:i], s.missions[i+1:]...)
+	s.completions = slices.DeleteFunc(s.completions, func(c models.Completion) bool { return c.MissionID == id })
+	s.submissions = slices.DeleteFunc(s.submissions, func(sub models.Submission) bool { return sub.MissionID == id })
+	delete(s.testCases, id)
+	return nil
+}
+
+// NormalizeMission fills the defaults of optional mission fields, matching
+// the missions table. Stores call it before writing a mission.
+func NormalizeMission(m *models.Mission) {
+	if m.Difficulty == "" {
+		m.Difficulty = models.DifficultyEasy
+	}
+	if m.Tags == nil {
+		m.Tags = []string{}
+	}
+}
+
+// findMission returns the index of the live mission in s.missions or -1.
+// Callers must hold s.mu.
+func (s *InMemoryStore) findMission(id int) int {
+	i := s.findAnyMission(id)
+	if i >= 0 && s.missions[i].DeletedAt != nil {
+		return -1
+	}
+	return i
+}
+
+// findAnyMission is like findMission but also finds deleted missions.
+func (s *InMemoryStore) findAnyMission(id int) int {
+	for i, m := range s.missions {
+		if m.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// ListMissions returns one page of missions matching opts.
+func (s *MissionService) ListMissions(ctx context.Context, opts ListOptions) (models.MissionPage, error) {
+	opts, err := opts.normalize()
+	if err != nil {
+		return models.MissionPage{}, err
+	}
+	return s.Store.ListMissions(ctx, opts)
+}
+
+// CreateMission validates m and adds it to the store with the caller in ctx
+// as its author.
+func (s *MissionService) CreateMission(ctx context.Context, m models.Mission) (models.Mission, error) {
+	if err := ValidateMission(m); err != nil {
+		return models.Mission{}, err
+	}
+	m.Title = strings.TrimSpace(m.Title)
+	m.AuthorID = CallerID(ctx)
+	return s.Store.AddMission(ctx, m)
+}
+
+// UpdateMission validates m and replaces the stored mission with the same
+// ID. Only admins and the mission's author may update it.
+func (s *MissionService) UpdateMission(ctx context.Context, m models.Mission) (models.Mission, error) {
+	if err := ValidateMission(m); err != nil {
+		return models.Mission{}, err
+	}
+	if _, err := s.editableMission(ctx, m.ID); err != nil {
+		return models.Mission{}, err
+	}
+	m.Title = strings.TrimSpace(m.Title)
+	return s.Store.UpdateMission(ctx, m)
+}
+
+// PatchMission applies an RFC 7396 merge patch to the stored mission,
+// validates the result and saves it. Fields absent from the patch keep
+// their values; null removes them. A non-zero version must match the
+// stored version.
+func (s *MissionService) PatchMission(ctx context.Context, id, version int, patch map[string]any) (models.Mission, error) {
+	current, err := s.editableMission(ctx, id)
+	if err != nil {
+		return models.Mission{}, err
+	}
+	if version != 0 && version != current.Version {
+		return models.Mission{}, ErrVersionMismatch
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return models.Mission{}, err
+	}
+	var target any
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return models.Mission{}, err
+	}
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return models.Mission{}, err
+	}
+
+	var m models.Mission
+	if err := json.Unmarshal(merged, &m); err != nil {
+		return models.Mission{}, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	// The identity is not patchable, and saving against the version read
+	// above keeps a concurrent update from being overwritten, so the
+	// permission checked on that version still holds.
+	m.ID = current.ID
+	m.Version = current.Version
+	m.CreatedAt = current.CreatedAt
+	if err := ValidateMission(m); err != nil {
+		return models.Mission{}, err
+	}
+	m.Title = strings.TrimSpace(m.Title)
+	return s.Store.UpdateMission(ctx, m)
+}
+
+// DeleteMission moves the mission to the trash. Only admins and the
+// mission's author may delete it.
+func (s *MissionService) DeleteMission(ctx context.Context, id, version int) error {
+	if _, err := s.editableMission(ctx, id); err != nil {
+		return err
+	}
+	return s.Store.DeleteMission(ctx, id, version)
+}
+
+// RestoreMission takes the mission out of the trash. Only admins and the
+// mission's author may restore it.
+func (s *MissionService) RestoreMission(ctx context.Context, id int) (models.Mission, error) {
+	m, err := s.Store.GetDeleted(ctx, id)
 	if err != nil {
+		return models.Mission{}, err
+	}
+	if !canEditMission(ctx, m) {
+		return models.Mission{}, ErrForbidden
+	}
+	return s.Store.RestoreMission(ctx, id)
+}
+
+// ListTrash returns one page of soft-deleted missions matching opts.
+func (s *MissionService) ListTrash(ctx context.Context, opts ListOptions) (models.MissionPage, error) {
+	opts.Deleted = true
+	return s.ListMissions(ctx, opts)
+}
+
+// CompleteMission records that the user completed the mission and returns
+// their updated profile. Completing the same mission twice returns ErrAlreadyCompleted.
+func (s *MissionService) CompleteMission(ctx context.Context, userID, missionID int) (models.Profile, error) {
+	if err := s.Users.AddCompletion(ctx, userID, missionID); err != nil {
+		return models.Profile{}, err
+	}
+	return s.completed(ctx, userID, missionID)
+}
+
+// CompleteMissionWithKey completes the mission like CompleteMission and
+// reserves the user's Idempotency-Key for the request to path in the same
+// transaction. A key that is already reserved gives ErrIdempotencyKeyTaken.
+func (s *MissionService) CompleteMissionWithKey(ctx context.Context, userID, missionID int, key, path string) (models.Profile, error) {
+	rec := models.IdempotencyRecord{Key: key, UserID: userID, Path: path}
+	if err := s.Idempotency.AddCompletionWithKey(ctx, rec, missionID); err != nil {
 		return models.Profile{}, err
 	}
+	return s.completed(ctx, userID, missionID)
+}
+
+// completed handles a recorded completion and returns the new profile.
+func (s *MissionService) completed(ctx context.Context, userID, missionID int) (models.Profile, error) {
+	if s.Logger != nil {
+		s.Logger.Info("mission completed", "user_id", userID, "mission_id", missionID)
+	}
+	s.publish(ctx, models.Event{Type: models.EventMissionCompleted, UserID: userID, MissionID: missionID})
+	s.recordActivity(ctx, userID)
+	return s.GetProfile(ctx, userID)
+}
+
+func totalPoints(missions []models.Mission) int {
 	total := 0
 	for _, m := range missions {
 		total += m.Points
+	}
+	return total
+}
 
+// GetProfile builds the profile of a single user from the missions they have
+// completed. With an AchievementStore it lists the awarded badges and does
+// not write: badges earned without an event, e.g. before the rules changed,
+// are awarded with the user's next event.
+func (s *MissionService) GetProfile(ctx context.Context, userID int) (models.Profile, error) {
+	if _, err := s.Users.GetUser(ctx, userID); err != nil {
+		return models.Profile{}, err
 	}
-	var level string
-	switch {
-	case total >= 1000:
-		level = "Expert"
-	case total >= 500:
-		level = "Advanced"
-	case total >= 200:
-		level = "Intermediate"
-	default:
-		level = "Beginner"
+	missions, err := s.Users.ListCompletedMissions(ctx, userID)
+	if err != nil {
+		return models.Profile{}, err
 	}
+	total := totalPoints(missions)
+	rules := s.GamificationRules()
+	profile := models.Profile{TotalPoints: total, Level: level(rules, total), Achievements: []string{}}
 
-	badges := []string{}
-	if total >= 200 {
-		badges = append(badges, "🏅200+ points")
+	if s.Streaks != nil {
+		st, err := s.Streaks.GetStreak(ctx, userID)
+		if err != nil {
+			return models.Profile{}, err
+		}
+		st = streakAsOf(st, s.now())
+		profile.CurrentStreak = st.Current
+		profile.LongestStreak = st.Longest
+		profile.StreakFreezes = st.Freezes
 	}
-
-	if total >= 500 {
-		badges = append(badges, "🎖 500+ points")
+	if s.Seasons != nil && s.Leaderboards != nil {
+		season, points, err := s.seasonPoints(ctx, userID)
+		if err != nil {
+			return models.Profile{}, err
+		}
+		profile.SeasonID, profile.SeasonPoints = season.ID, points
 	}
-	if total >= 1000 {
-		badges = append(badges, "🏆 1000+ points")
+
+	if s.Achievements == nil {
+		for _, b := range rules.Badges {
+			ok, err := s.badgeEarned(ctx, b, missions, nil)
+			if err != nil {
+				return models.Profile{}, err
+			}
+			if ok {
+				profile.Achievements = append(profile.Achievements, b.Name)
+			}
+		}
+		return profile, nil
 	}
 
-	return models.Profile{
-		TotalPoints:  total,
-		Level:        level,
-		Achievements: badges}, nil
+	awarded, err := s.Achievements.ListAchievements(ctx, userID)
+	if err != nil {
+		return models.Profile{}, err
+	}
+	for _, a := range awarded {
+		profile.Achievements = append(profile.Achievements, a.Name)
+	}
+	profile.Badges = awarded
+	return profile, nil
 }
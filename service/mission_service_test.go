@@ -2,8 +2,10 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"github.com/pseudoerr/mission-service/models"
 	"github.com/pseudoerr/mission-service/service"
+	"sync"
 	"testing"
 )
 
@@ -29,14 +31,14 @@ func TestAddMission(t *testing.T) {
 		t.Errorf("expected title %s, got %s", newM.Title, added.Title)
 	}
 
-	missions, err := store.ListMissions(context.Background())
+	page, err := store.ListMissions(context.Background(), service.ListOptions{Limit: service.MaxListLimit})
 	if err != nil {
 		t.Fatalf("could not list missions: %v", err)
 	}
 
 	found := false
 
-	for _, m := range missions {
+	for _, m := range page.Items {
 		if m.ID == added.ID {
 			found = true
 			break
@@ -46,3 +48,350 @@ func TestAddMission(t *testing.T) {
 		t.Errorf("added mission not found in store")
 	}
 }
+
+func TestInMemoryStoreGetUpdateDelete(t *testing.T) {
+	store := service.NewInMemoryStore()
+	ctx := context.Background()
+
+	m, err := store.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m.Title = "Updated"
+	if _, err := store.UpdateMission(ctx, m); err != nil {
+		t.Fatalf("could not update mission: %v", err)
+	}
+
+	got, err := store.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Title != "Updated" {
+		t.Errorf("expected title Updated, got %s", got.Title)
+	}
+
+	if _, err := store.UpdateMission(ctx, m); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch for a stale version, got %v", err)
+	}
+	if err := store.DeleteMission(ctx, 1, m.Version); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch on delete, got %v", err)
+	}
+
+	if err := store.DeleteMission(ctx, 1, got.Version); err != nil {
+		t.Fatalf("could not delete mission: %v", err)
+	}
+
+	if _, err := store.GetByID(ctx, 1); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestGetProfileCountsOnlyUserCompletions(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store}
+	ctx := context.Background()
+
+	other, err := store.AddUser(ctx, models.User{Username: "other"})
+	if err != nil {
+		t.Fatalf("could not add user: %v", err)
+	}
+	if err := store.AddCompletion(ctx, 1, 2); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+	if err := store.AddCompletion(ctx, other.ID, 1); err != nil {
+		t.Fatalf("could not add completion: %v", err)
+	}
+
+	profile, err := svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.TotalPoints != 200 {
+		t.Errorf("expected 200 points, got %d", profile.TotalPoints)
+	}
+	if profile.Level != "Intermediate" {
+		t.Errorf("expected level Intermediate, got %s", profile.Level)
+	}
+
+	if _, err := svc.GetProfile(ctx, 999); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown user, got %v", err)
+	}
+}
+
+func TestCompleteMissionRejectsDuplicates(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store}
+	ctx := context.Background()
+
+	profile, err := svc.CompleteMission(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.TotalPoints != 100 {
+		t.Errorf("expected 100 points, got %d", profile.TotalPoints)
+	}
+
+	if _, err := svc.CompleteMission(ctx, 1, 1); !errors.Is(err, service.ErrAlreadyCompleted) {
+		t.Errorf("expected ErrAlreadyCompleted, got %v", err)
+	}
+	if _, err := svc.CompleteMission(ctx, 1, 999); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown mission, got %v", err)
+	}
+}
+
+func TestCompleteMissionWithKey(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Idempotency: store}
+	ctx := context.Background()
+
+	// A completion that fails does not take the key.
+	if _, err := svc.CompleteMissionWithKey(ctx, 1, 999, "k1", "/missions/999/complete"); !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.GetIdempotencyRecord(ctx, 1, "k1"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected the key to stay free, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = svc.CompleteMissionWithKey(ctx, 1, 1, "k2", "/missions/1/complete")
+		}()
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) || !errors.Is(errors.Join(errs...), service.ErrIdempotencyKeyTaken) {
+		t.Errorf("expected one completion and one taken key, got %v", errs)
+	}
+	rec, err := store.GetIdempotencyRecord(ctx, 1, "k2")
+	if err != nil || rec.StatusCode != 0 || rec.Path != "/missions/1/complete" {
+		t.Errorf("expected a reserved key, got %+v (%v)", rec, err)
+	}
+}
+
+func TestInMemoryStoreMissionDefaults(t *testing.T) {
+	store := service.NewInMemoryStore()
+	ctx := context.Background()
+
+	added, err := store.AddMission(ctx, models.Mission{Title: "Two Sum", Points: 150})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if added.Difficulty != models.DifficultyEasy {
+		t.Errorf("expected default difficulty easy, got %s", added.Difficulty)
+	}
+	if added.Tags == nil {
+		t.Errorf("expected empty tags, got nil")
+	}
+	if added.CreatedAt.IsZero() || !added.UpdatedAt.Equal(added.CreatedAt) {
+		t.Errorf("unexpected timestamps: created %v, updated %v", added.CreatedAt, added.UpdatedAt)
+	}
+
+	added.Difficulty = models.DifficultyHard
+	updated, err := store.UpdateMission(ctx, added)
+	if err != nil {
+		t.Fatalf("could not update mission: %v", err)
+	}
+	if !updated.CreatedAt.Equal(added.CreatedAt) {
+		t.Errorf("expected created_at to be preserved")
+	}
+	if updated.UpdatedAt.Before(added.UpdatedAt) {
+		t.Errorf("expected updated_at to move forward")
+	}
+}
+
+func TestInMemoryStoreListMissionsFiltersAndPaginates(t *testing.T) {
+	store := service.NewInMemoryStore()
+	ctx := context.Background()
+
+	for _, m := range []models.Mission{
+		{Title: "Binary Search", Points: 300, Difficulty: models.DifficultyMedium, Tags: []string{"search"}},
+		{Title: "Graph Coloring", Points: 500, Difficulty: models.DifficultyHard, Tags: []string{"graphs"}},
+		{Title: "Two Pointers", Points: 300, Difficulty: models.DifficultyMedium, Tags: []string{"arrays"}},
+	} {
+		if _, err := store.AddMission(ctx, m); err != nil {
+			t.Fatalf("could not add mission: %v", err)
+		}
+	}
+
+	minPoints := 200
+	opts := service.ListOptions{Difficulty: models.DifficultyMedium, MinPoints: &minPoints, Sort: service.SortPointsDesc, Limit: 2}
+
+	first, err := store.ListMissions(ctx, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(first.Items) != 2 || first.NextCursor == "" {
+		t.Fatalf("expected full first page with cursor, got %+v", first)
+	}
+	if first.Items[0].Title != "Two Pointers" || first.Items[1].Title != "Binary Search" {
+		t.Errorf("unexpected order: %s, %s", first.Items[0].Title, first.Items[1].Title)
+	}
+
+	opts.Cursor = first.NextCursor
+	second, err := store.ListMissions(ctx, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(second.Items) != 1 || second.Items[0].Title != "FizzBuzz" || second.NextCursor != "" {
+		t.Errorf("unexpected second page: %+v", second)
+	}
+
+	tagged, err := store.ListMissions(ctx, service.ListOptions{Tag: "graphs"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tagged.Items) != 1 || tagged.Items[0].Title != "Graph Coloring" {
+		t.Errorf("unexpected tag filter result: %+v", tagged.Items)
+	}
+
+	if _, err := store.ListMissions(ctx, service.ListOptions{Sort: "title"}); !errors.Is(err, service.ErrInvalidSort) {
+		t.Errorf("expected ErrInvalidSort, got %v", err)
+	}
+	if _, err := store.ListMissions(ctx, service.ListOptions{Sort: service.SortPoints, Cursor: first.NextCursor}); !errors.Is(err, service.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor for a cursor of another sort order, got %v", err)
+	}
+}
+
+func TestInMemoryStoreUpdateDeleteUnknownMission(t *testing.T) {
+	store := service.NewInMemoryStore()
+	ctx := context.Background()
+
+	if _, err := store.UpdateMission(ctx, models.Mission{ID: 999, Title: "Ghost", Points: 10}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on update, got %v", err)
+	}
+	if err := store.DeleteMission(ctx, 999, 0); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on delete, got %v", err)
+	}
+	if err := store.DeleteMission(ctx, 1, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.DeleteMission(ctx, 1, 0); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on second delete, got %v", err)
+	}
+}
+
+func TestPatchMissionMergesFields(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store}
+	ctx := service.WithPrincipal(context.Background(), models.Principal{UserID: 1, Role: models.RoleAdmin})
+
+	patch := map[string]any{
+		"title": "Hello again",
+		"tags":  nil,
+		"extra": map[string]any{"ignored": true},
+	}
+	got, err := svc.PatchMission(ctx, 1, 1, patch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Title != "Hello again" || got.Points != 100 || got.Difficulty != models.DifficultyEasy {
+		t.Errorf("expected only the title to change, got %+v", got)
+	}
+	if len(got.Tags) != 0 {
+		t.Errorf("expected null to clear tags, got %v", got.Tags)
+	}
+	if got.Version != 2 {
+		t.Errorf("expected version 2, got %d", got.Version)
+	}
+
+	if _, err := svc.PatchMission(ctx, 1, 1, map[string]any{"points": 5}); !errors.Is(err, service.ErrVersionMismatch) {
+		t.Errorf("expected ErrVersionMismatch for a stale version, got %v", err)
+	}
+	if _, err := svc.PatchMission(ctx, 1, 0, map[string]any{"points": 0}); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("expected ErrValidation for invalid points, got %v", err)
+	}
+	if _, err := svc.PatchMission(ctx, 999, 0, map[string]any{"points": 5}); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestInMemoryStoreSoftDelete(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store}
+	ctx := context.Background()
+
+	if _, err := svc.CompleteMission(ctx, 1, 1); err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if err := store.DeleteMission(ctx, 1, 0); err != nil {
+		t.Fatalf("could not delete mission: %v", err)
+	}
+
+	if _, err := store.GetByID(ctx, 1); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected deleted mission to be hidden, got %v", err)
+	}
+	page, err := svc.ListMissions(ctx, service.ListOptions{})
+	if err != nil || len(page.Items) != 1 || page.Items[0].ID != 2 {
+		t.Errorf("expected only mission 2 to be listed, got %+v (%v)", page.Items, err)
+	}
+	trash, err := svc.ListTrash(ctx, service.ListOptions{})
+	if err != nil || len(trash.Items) != 1 || trash.Items[0].ID != 1 || trash.Items[0].DeletedAt == nil {
+		t.Errorf("expected mission 1 in the trash, got %+v (%v)", trash.Items, err)
+	}
+	if profile, err := svc.GetProfile(ctx, 1); err != nil || profile.TotalPoints != 100 {
+		t.Errorf("expected completed points to survive deletion, got %+v (%v)", profile, err)
+	}
+
+	restored, err := store.RestoreMission(ctx, 1)
+	if err != nil || restored.DeletedAt != nil {
+		t.Fatalf("could not restore mission: %+v (%v)", restored, err)
+	}
+	if _, err := store.RestoreMission(ctx, 1); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound restoring a live mission, got %v", err)
+	}
+
+	if err := store.PurgeMission(ctx, 1); err != nil {
+		t.Fatalf("could not purge mission: %v", err)
+	}
+	if profile, err := svc.GetProfile(ctx, 1); err != nil || profile.TotalPoints != 0 {
+		t.Errorf("expected purge to drop completions, got %+v (%v)", profile, err)
+	}
+	if err := store.PurgeMission(ctx, 1); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound on second purge, got %v", err)
+	}
+}
+
+func TestMissionOwnership(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store}
+	as := func(userID int, role models.Role) context.Context {
+		return service.WithPrincipal(context.Background(), models.Principal{UserID: userID, Role: role})
+	}
+	author, other := as(5, models.RoleAuthor), as(6, models.RoleAuthor)
+
+	m, err := svc.CreateMission(author, models.Mission{Title: "Two Sum", Points: 10})
+	if err != nil {
+		t.Fatalf("could not create mission: %v", err)
+	}
+	if m.AuthorID != 5 {
+		t.Errorf("expected author 5, got %d", m.AuthorID)
+	}
+
+	if _, err := svc.UpdateMission(other, m); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another author, got %v", err)
+	}
+	if _, err := svc.UpdateMission(context.Background(), m); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("expected ErrForbidden without a principal, got %v", err)
+	}
+	m.Title = "Two Sum II"
+	if _, err := svc.UpdateMission(author, m); err != nil {
+		t.Errorf("author could not update own mission: %v", err)
+	}
+
+	if err := svc.DeleteMission(other, m.ID, 0); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("expected ErrForbidden deleting another's mission, got %v", err)
+	}
+	if err := svc.DeleteMission(author, m.ID, 0); err != nil {
+		t.Fatalf("author could not delete own mission: %v", err)
+	}
+	if _, err := svc.RestoreMission(other, m.ID); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("expected ErrForbidden restoring another's mission, got %v", err)
+	}
+	if _, err := svc.RestoreMission(as(1, models.RoleAdmin), m.ID); err != nil {
+		t.Errorf("admin could not restore mission: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated caller p.
+func WithPrincipal(ctx context.Context, p models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller stored in ctx by WithPrincipal.
+func PrincipalFromContext(ctx context.Context) (models.Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(models.Principal)
+	return p, ok
+}
+
+// CallerID returns the ID of the authenticated user, or 0 for anonymous requests.
+func CallerID(ctx context.Context) int {
+	p, _ := PrincipalFromContext(ctx)
+	return p.UserID
+}
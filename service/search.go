@@ -0,0 +1,97 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// ErrEmptyQuery is returned when a search query contains no searchable terms.
+var ErrEmptyQuery = fmt.Errorf("empty search query: %w", ErrValidation)
+
+// SearchMissions returns missions whose title or description contain every
+// term of query, best matches first.
+func (s *MissionService) SearchMissions(ctx context.Context, query string, limit int) ([]models.Mission, error) {
+	if len(tokenize(query)) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+	return s.Store.SearchMissions(ctx, query, limit)
+}
+
+// SearchMissions scores missions like the weighted tsvector of the Postgres
+// store: a term found in the title counts twice as much as one found in the
+// description.
+func (s *InMemoryStore) SearchMissions(ctx context.Context, query string, limit int) ([]models.Mission, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return []models.Mission{}, nil
+	}
+
+	type scored struct {
+		mission models.Mission
+		score   int
+	}
+
+	s.mu.Lock()
+	var hits []scored
+	for _, m := range s.missions {
+		if m.DeletedAt != nil {
+			continue
+		}
+		title, description := tokenize(m.Title), tokenize(m.Description)
+		score := 0
+		for _, term := range terms {
+			n := 2*countToken(title, term) + countToken(description, term)
+			if n == 0 {
+				score = 0
+				break
+			}
+			score += n
+		}
+		if score > 0 {
+			hits = append(hits, scored{mission: m, score: score})
+		}
+	}
+	s.mu.Unlock()
+
+	slices.SortStableFunc(hits, func(a, b scored) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.mission.ID, b.mission.ID)
+	})
+
+	missions := []models.Mission{}
+	for i := 0; i < len(hits) && i < limit; i++ {
+		missions = append(missions, hits[i].mission)
+	}
+	return missions, nil
+}
+
+// tokenize splits s into lower-cased words made of letters and digits.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func countToken(tokens []string, term string) int {
+	n := 0
+	for _, t := range tokens {
+		if t == term {
+			n++
+		}
+	}
+	return n
+}
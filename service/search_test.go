@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestSearchMissionsRanksTitleMatchesFirst(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store}
+	ctx := context.Background()
+
+	for _, m := range []models.Mission{
+		{Title: "Sorting Basics", Description: "Implement bubble sort."},
+		{Title: "Bubble Sort", Description: "Sort an array using bubble sort."},
+		{Title: "Queues", Description: "Implement a queue."},
+	} {
+		if _, err := store.AddMission(ctx, m); err != nil {
+			t.Fatalf("could not add mission: %v", err)
+		}
+	}
+
+	found, err := svc.SearchMissions(ctx, "Bubble sort", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(found))
+	}
+	if found[0].Title != "Bubble Sort" {
+		t.Errorf("expected title match first, got %s", found[0].Title)
+	}
+
+	none, err := svc.SearchMissions(ctx, "bubble queue", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(none) != 0 {
+		t.Errorf("expected every term to be required, got %d results", len(none))
+	}
+
+	if _, err := svc.SearchMissions(ctx, "  !? ", 10); !errors.Is(err, service.ErrEmptyQuery) {
+		t.Errorf("expected ErrEmptyQuery, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// QuarterStart returns the start of the quarter containing t: the first of
+// January, April, July or October at 00:00 UTC. Seasons run for a quarter.
+func QuarterStart(t time.Time) time.Time {
+	t = t.UTC()
+	month := t.Month() - (t.Month()-1)%3
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ListSeasons returns every season, the latest first.
+func (s *MissionService) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	return s.Seasons.ListSeasons(ctx)
+}
+
+// CurrentSeason returns the season running now or ErrNotFound between the
+// end of one season and the rollover that starts the next.
+func (s *MissionService) CurrentSeason(ctx context.Context) (models.Season, error) {
+	return s.Seasons.SeasonAt(ctx, s.now())
+}
+
+// SeasonLeaderboard returns a page of the season's leaderboard: the final
+// standings once the season is archived, the live ranking of its
+// completions before. Paging works as for Leaderboard.
+func (s *MissionService) SeasonLeaderboard(ctx context.Context, id int, cursor string, limit int) (models.LeaderboardPage, error) {
+	season, err := s.Seasons.GetSeason(ctx, id)
+	if err != nil {
+		return models.LeaderboardPage{}, err
+	}
+	if season.ArchivedAt != nil {
+		return leaderboardPage(cursor, limit, func(after *LeaderboardCursor, limit int) ([]models.LeaderboardEntry, error) {
+			return s.Seasons.SeasonStandings(ctx, id, after, limit)
+		})
+	}
+	return s.Leaderboard(ctx, LeaderboardQuery{Since: season.StartsAt, Until: season.EndsAt}, cursor, limit)
+}
+
+// seasonPoints returns the current season and the user's points in it; a
+// zero season when there is none.
+func (s *MissionService) seasonPoints(ctx context.Context, userID int) (models.Season, int, error) {
+	season, err := s.CurrentSeason(ctx)
+	if errors.Is(err, ErrNotFound) {
+		return models.Season{}, 0, nil
+	}
+	if err != nil {
+		return models.Season{}, 0, err
+	}
+	points, err := s.Leaderboards.UserPoints(ctx, userID, LeaderboardQuery{Since: season.StartsAt, Until: season.EndsAt})
+	return season, points, err
+}
+
+// RolloverSeasons archives the final standings of every season that has
+// ended and starts the season of the current quarter if there is none.
+// Running it from several instances at once is safe.
+func (s *MissionService) RolloverSeasons(ctx context.Context) error {
+	now := s.now()
+	seasons, err := s.Seasons.ListSeasons(ctx)
+	if err != nil {
+		return err
+	}
+
+	current := false
+	for _, season := range seasons {
+		current = current || season.Contains(now)
+		if season.ArchivedAt != nil || now.Before(season.EndsAt) {
+			continue
+		}
+		_, err := s.Seasons.ArchiveSeason(ctx, season.ID, now)
+		if errors.Is(err, ErrConflict) {
+			continue // archived by another instance
+		}
+		if err != nil {
+			return fmt.Errorf("archive season %d: %w", season.ID, err)
+		}
+		if s.Logger != nil {
+			s.Logger.Info("season archived", "season_id", season.ID, "name", season.Name)
+		}
+	}
+	if current {
+		return nil
+	}
+
+	start := QuarterStart(now)
+	season, err := s.Seasons.AddSeason(ctx, models.Season{
+		Name:     fmt.Sprintf("%d Q%d", start.Year(), (start.Month()-1)/3+1),
+		StartsAt: start,
+		EndsAt:   start.AddDate(0, 3, 0),
+	})
+	if errors.Is(err, ErrConflict) {
+		return nil // started by another instance
+	}
+	if err != nil {
+		return fmt.Errorf("start season: %w", err)
+	}
+	if s.Logger != nil {
+		s.Logger.Info("season started", "season_id", season.ID, "name", season.Name)
+	}
+	return nil
+}
+
+// SeasonRollover runs RolloverSeasons in the background.
+type SeasonRollover struct {
+	Service *MissionService
+	// Interval is the time between rollovers and must be positive; a
+	// season ending is noticed at most this late.
+	Interval time.Duration
+	Logger   *slog.Logger
+}
+
+// Run rolls seasons over right away and then every Interval until ctx is
+// cancelled.
+func (r *SeasonRollover) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Service.RolloverSeasons(ctx); err != nil && ctx.Err() == nil {
+			r.logger().Error("season rollover failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *SeasonRollover) logger() *slog.Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// SeasonStore persists seasons and the final standings of archived ones.
+type SeasonStore interface {
+	// AddSeason stores a new season. It returns ErrConflict when a season
+	// with the same start exists.
+	AddSeason(ctx context.Context, s models.Season) (models.Season, error)
+	GetSeason(ctx context.Context, id int) (models.Season, error)
+	// ListSeasons returns every season, the latest first.
+	ListSeasons(ctx context.Context) ([]models.Season, error)
+	// SeasonAt returns the season running at t, or ErrNotFound when there
+	// is none.
+	SeasonAt(ctx context.Context, t time.Time) (models.Season, error)
+	// ArchiveSeason stores the leaderboard of the season's completions as
+	// its final standings and marks it archived at the given time, both at
+	// once. It returns ErrConflict when the season is already archived.
+	ArchiveSeason(ctx context.Context, id int, at time.Time) (models.Season, error)
+	// SeasonStandings returns up to limit archived entries following after,
+	// from the top when it is nil.
+	SeasonStandings(ctx context.Context, id int, after *LeaderboardCursor, limit int) ([]models.LeaderboardEntry, error)
+}
+
+var _ SeasonStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) AddSeason(ctx context.Context, season models.Season) (models.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.seasons {
+		if existing.StartsAt.Equal(season.StartsAt) {
+			return models.Season{}, ErrConflict
+		}
+	}
+	s.nextSeasonID++
+	season.ID = s.nextSeasonID
+	season.ArchivedAt = nil
+	s.seasons = append(s.seasons, season)
+	return season, nil
+}
+
+func (s *InMemoryStore) GetSeason(ctx context.Context, id int) (models.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findSeason(id)
+	if i < 0 {
+		return models.Season{}, ErrNotFound
+	}
+	return s.seasons[i], nil
+}
+
+func (s *InMemoryStore) ListSeasons(ctx context.Context) ([]models.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seasons := append([]models.Season{}, s.seasons...)
+	slices.SortFunc(seasons, func(a, b models.Season) int { return b.StartsAt.Compare(a.StartsAt) })
+	return seasons, nil
+}
+
+func (s *InMemoryStore) SeasonAt(ctx context.Context, t time.Time) (models.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, season := range s.seasons {
+		if season.Contains(t) {
+			return season, nil
+		}
+	}
+	return models.Season{}, ErrNotFound
+}
+
+func (s *InMemoryStore) ArchiveSeason(ctx context.Context, id int, at time.Time) (models.Season, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findSeason(id)
+	if i < 0 {
+		return models.Season{}, ErrNotFound
+	}
+	if s.seasons[i].ArchivedAt != nil {
+		return models.Season{}, ErrConflict
+	}
+	if s.standings == nil {
+		s.standings = make(map[int][]models.LeaderboardEntry)
+	}
+	s.standings[id] = s.rankedEntries(LeaderboardQuery{Since: s.seasons[i].StartsAt, Until: s.seasons[i].EndsAt})
+	s.seasons[i].ArchivedAt = &at
+	return s.seasons[i], nil
+}
+
+func (s *InMemoryStore) SeasonStandings(ctx context.Context, id int, after *LeaderboardCursor, limit int) ([]models.LeaderboardEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return entriesAfter(s.standings[id], after, limit), nil
+}
+
+func (s *InMemoryStore) findSeason(id int) int {
+	return slices.IndexFunc(s.seasons, func(season models.Season) bool { return season.ID == id })
+}
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestQuarterStart(t *testing.T) {
+	cases := map[time.Time]time.Time{
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC):                        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 30, 23, 59, 0, 0, time.UTC):                     time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC):                     time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 7, 1, 1, 0, 0, 0, time.FixedZone("UTC+3", 3*3600)): time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for in, want := range cases {
+		if got := service.QuarterStart(in); !got.Equal(want) {
+			t.Errorf("QuarterStart(%v): expected %v, got %v", in, want, got)
+		}
+	}
+}
+
+func TestSeasonRollover(t *testing.T) {
+	store := service.NewInMemoryStore()
+	clock := &fakeClock{now: time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)}
+	store.Clock = clock
+	svc := &service.MissionService{Store: store, Users: store, Leaderboards: store, Seasons: store, Clock: clock}
+	ctx := context.Background()
+
+	if err := svc.RolloverSeasons(ctx); err != nil {
+		t.Fatalf("could not start season: %v", err)
+	}
+	first, err := svc.CurrentSeason(ctx)
+	if err != nil || !first.StartsAt.Equal(service.QuarterStart(clock.now)) || !first.EndsAt.Equal(first.StartsAt.AddDate(0, 3, 0)) {
+		t.Fatalf("unexpected current season %+v (%v)", first, err)
+	}
+	profile, err := svc.CompleteMission(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if profile.SeasonID != first.ID || profile.SeasonPoints != 100 || profile.TotalPoints != 100 {
+		t.Errorf("expected 100 season and lifetime points, got %+v", profile)
+	}
+
+	clock.now = first.EndsAt.Add(time.Minute)
+	for range 2 {
+		if err := svc.RolloverSeasons(ctx); err != nil {
+			t.Fatalf("could not roll seasons over: %v", err)
+		}
+	}
+	seasons, err := svc.ListSeasons(ctx)
+	if err != nil || len(seasons) != 2 || seasons[1].ID != first.ID || seasons[1].ArchivedAt == nil || !seasons[0].StartsAt.Equal(first.EndsAt) {
+		t.Fatalf("expected the first season archived and a second one, got %+v (%v)", seasons, err)
+	}
+
+	// Completions after the rollover count toward the new season only.
+	profile, err = svc.CompleteMission(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if profile.SeasonID != seasons[0].ID || profile.SeasonPoints != 200 || profile.TotalPoints != 300 {
+		t.Errorf("expected 200 points in the new season, got %+v", profile)
+	}
+	live, err := svc.SeasonLeaderboard(ctx, seasons[0].ID, "", 0)
+	if err != nil || len(live.Items) != 1 || live.Items[0].Points != 200 {
+		t.Errorf("expected the new completion on the live leaderboard, got %+v (%v)", live.Items, err)
+	}
+
+	// Purging a mission no longer changes the final standings.
+	if err := store.PurgeMission(ctx, 1); err != nil {
+		t.Fatalf("could not purge mission: %v", err)
+	}
+	page, err := svc.SeasonLeaderboard(ctx, first.ID, "", 0)
+	if err != nil || len(page.Items) != 1 || page.Items[0] != (models.LeaderboardEntry{Rank: 1, UserID: 1, Username: "demo", Points: 100, Completed: 1}) {
+		t.Errorf("unexpected final standings %+v (%v)", page.Items, err)
+	}
+
+	if _, err := store.ArchiveSeason(ctx, first.ID, clock.now); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("expected ErrConflict archiving twice, got %v", err)
+	}
+	if _, err := store.AddSeason(ctx, models.Season{StartsAt: first.StartsAt, EndsAt: first.EndsAt}); !errors.Is(err, service.ErrConflict) {
+		t.Errorf("expected ErrConflict for a duplicate season, got %v", err)
+	}
+	if _, err := svc.SeasonLeaderboard(ctx, 999, "", 0); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown season, got %v", err)
+	}
+}
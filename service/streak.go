@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"time"
+	// Embedded so that user timezones resolve without a system tz database.
+	_ "time/tzdata"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// Clock tells the current time. Tests replace it to move through days.
+type Clock interface {
+	Now() time.Time
+}
+
+func (s *MissionService) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+	return s.Clock.Now()
+}
+
+// GetStreak returns the user's streak as of now. Like on the profile, the
+// freezes exclude those already used up by missed days, and the current
+// streak is 0 once more days were missed than the freezes cover.
+func (s *MissionService) GetStreak(ctx context.Context, userID int) (models.Streak, error) {
+	if _, err := s.Users.GetUser(ctx, userID); err != nil {
+		return models.Streak{}, err
+	}
+	st, err := s.Streaks.GetStreak(ctx, userID)
+	if err != nil {
+		return models.Streak{}, err
+	}
+	return streakAsOf(st, s.now()), nil
+}
+
+// SetStreakTimezone changes the timezone whose midnight starts the user's
+// days. Users may change their own timezone, admins every user's. After
+// being active today a user cannot move to a timezone where tomorrow has
+// already begun, which would count a second day within the same one.
+func (s *MissionService) SetStreakTimezone(ctx context.Context, userID int, timezone string) (models.Streak, error) {
+	if !canManageUser(ctx, userID) {
+		return models.Streak{}, ErrForbidden
+	}
+	var v ValidationError
+	if _, err := loadTimezone(timezone); err != nil {
+		v.add("timezone", "must be an IANA time zone name such as Europe/Berlin")
+	}
+	if err := v.err(); err != nil {
+		return models.Streak{}, err
+	}
+	if _, err := s.Users.GetUser(ctx, userID); err != nil {
+		return models.Streak{}, err
+	}
+
+	now := s.now()
+	st, err := s.Streaks.UpdateStreak(ctx, userID, func(st models.Streak) (models.Streak, error) {
+		today := streakDay(st, now)
+		moved := st
+		moved.Timezone = timezone
+		if last, ok := lastActive(st); ok && !last.Before(today) && streakDay(moved, now).After(today) {
+			var early ValidationError
+			early.add("timezone", "starts a new day before today has ended in the current timezone")
+			return st, early.err()
+		}
+		return moved, nil
+	})
+	if err != nil {
+		return models.Streak{}, err
+	}
+	return streakAsOf(st, now), nil
+}
+
+// recordActivity counts today, in the user's timezone, as a day of
+// activity and hands out freezes for the user's points. Like publish it
+// only logs failures. Extending the streak raises a streak_extended event.
+func (s *MissionService) recordActivity(ctx context.Context, userID int) {
+	if s.Streaks == nil {
+		return
+	}
+	now := s.now()
+	rules := s.GamificationRules().Streaks
+	extended := false
+
+	completed, err := s.Users.ListCompletedMissions(ctx, userID)
+	var st models.Streak
+	if err == nil {
+		st, err = s.Streaks.UpdateStreak(ctx, userID, func(st models.Streak) (models.Streak, error) {
+			st, extended = extendStreak(st, streakDay(st, now))
+			return earnFreezes(st, rules, totalPoints(completed)), nil
+		})
+	}
+	if err != nil {
+		if s.Logger != nil {
+			s.Logger.Warn("could not update streak", "user_id", userID, "error", err)
+		}
+		return
+	}
+	if extended {
+		s.publish(ctx, models.Event{Type: models.EventStreakExtended, UserID: userID, Streak: st.Current, At: now})
+	}
+}
+
+// extendStreak counts day as active. A day right after the last active one
+// extends the streak, and so does a later one if the freezes cover every
+// missed day in between; otherwise the streak starts over. It reports
+// whether day was a new active day.
+func extendStreak(st models.Streak, day time.Time) (models.Streak, bool) {
+	if last, ok := lastActive(st); ok {
+		missed := daysBetween(last, day) - 1
+		switch {
+		case missed < 0:
+			return st, false
+		case missed <= st.Freezes:
+			st.Freezes -= missed
+			st.Current++
+		default:
+			st.Current = 1
+		}
+	} else {
+		st.Current = 1
+	}
+	st.LastActive = day.Format(time.DateOnly)
+	st.Longest = max(st.Longest, st.Current)
+	return st, true
+}
+
+// earnFreezes adds a freeze for every rules.FreezePoints of points not yet
+// rewarded, holding at most rules.MaxFreezes.
+func earnFreezes(st models.Streak, rules models.StreakRules, points int) models.Streak {
+	if rules.FreezePoints <= 0 {
+		return st
+	}
+	earned := points / rules.FreezePoints
+	if earned > st.FreezesEarned {
+		st.Freezes = min(rules.MaxFreezes, st.Freezes+earned-st.FreezesEarned)
+		st.FreezesEarned = earned
+	}
+	return st
+}
+
+// streakAsOf returns the streak as of now. A freeze is used up for every
+// day missed since the last active one, as the next activity would do;
+// when they do not cover all of them the current streak is 0.
+func streakAsOf(st models.Streak, now time.Time) models.Streak {
+	last, ok := lastActive(st)
+	if !ok {
+		st.Current = 0
+		return st
+	}
+	missed := daysBetween(last, streakDay(st, now)) - 1
+	switch {
+	case missed <= 0:
+	case missed <= st.Freezes:
+		st.Freezes -= missed
+	default:
+		st.Current = 0
+	}
+	return st
+}
+
+// streakDay returns the date of t in the streak's timezone as midnight UTC,
+// so that days can be counted without daylight saving shifts.
+func streakDay(st models.Streak, t time.Time) time.Time {
+	loc, err := loadTimezone(st.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func lastActive(st models.Streak) (time.Time, bool) {
+	if st.LastActive == "" {
+		return time.Time{}, false
+	}
+	day, err := time.Parse(time.DateOnly, st.LastActive)
+	return day, err == nil
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// loadTimezone loads an IANA timezone. Unlike time.LoadLocation it rejects
+// the empty name and "Local", which depend on the server.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, ErrValidation
+	}
+	return time.LoadLocation(name)
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// DefaultTimezone is the timezone of users who have not set one.
+const DefaultTimezone = "UTC"
+
+// StreakStore persists daily activity streaks.
+type StreakStore interface {
+	// GetStreak returns the user's streak, a zero streak in DefaultTimezone
+	// when the user has none yet.
+	GetStreak(ctx context.Context, userID int) (models.Streak, error)
+	// UpdateStreak stores the result of update applied to the user's
+	// current streak. Concurrent updates of one user are serialized, and
+	// nothing is stored when update fails.
+	UpdateStreak(ctx context.Context, userID int, update func(models.Streak) (models.Streak, error)) (models.Streak, error)
+}
+
+var _ StreakStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) GetStreak(ctx context.Context, userID int) (models.Streak, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streak(userID), nil
+}
+
+func (s *InMemoryStore) UpdateStreak(ctx context.Context, userID int, update func(models.Streak) (models.Streak, error)) (models.Streak, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findUser(userID) < 0 {
+		return models.Streak{}, ErrNotFound
+	}
+	st, err := update(s.streak(userID))
+	if err != nil {
+		return models.Streak{}, err
+	}
+	st.UserID = userID
+	if s.streaks == nil {
+		s.streaks = make(map[int]models.Streak)
+	}
+	s.streaks[userID] = st
+	return st, nil
+}
+
+func (s *InMemoryStore) streak(userID int) models.Streak {
+	if st, ok := s.streaks[userID]; ok {
+		return st
+	}
+	return models.Streak{UserID: userID, Timezone: DefaultTimezone}
+}
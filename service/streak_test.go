@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func TestStreaks(t *testing.T) {
+	store := service.NewInMemoryStore()
+	clock := &fakeClock{}
+	svc := &service.MissionService{
+		Store: store, Users: store, Achievements: store, Streaks: store, Clock: clock,
+		Rules: models.GamificationRules{
+			Levels:  []models.Level{{Name: "Beginner"}},
+			Badges:  []models.Badge{{ID: "streak-3", Name: "Three days", MinStreak: 3}},
+			Streaks: models.StreakRules{FreezePoints: 300, MaxFreezes: 1},
+		},
+	}
+	ctx := service.WithPrincipal(context.Background(), models.Principal{UserID: 1, Role: models.RolePlayer})
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("could not load timezone: %v", err)
+	}
+	if _, err := svc.SetStreakTimezone(ctx, 1, "America/New_York"); err != nil {
+		t.Fatalf("could not set timezone: %v", err)
+	}
+
+	mission := func(points int) int {
+		m, err := store.AddMission(ctx, models.Mission{Title: "Daily", Category: "daily", Points: points})
+		if err != nil {
+			t.Fatalf("could not add mission: %v", err)
+		}
+		return m.ID
+	}
+	complete := func(at time.Time, missionID int) models.Profile {
+		t.Helper()
+		clock.now = at
+		profile, err := svc.CompleteMission(ctx, 1, missionID)
+		if err != nil {
+			t.Fatalf("could not complete mission: %v", err)
+		}
+		return profile
+	}
+	check := func(step string, p models.Profile, current, longest, freezes int) {
+		t.Helper()
+		if p.CurrentStreak != current || p.LongestStreak != longest || p.StreakFreezes != freezes {
+			t.Errorf("%s: expected streak %d/%d with %d freezes, got %d/%d with %d",
+				step, current, longest, freezes, p.CurrentStreak, p.LongestStreak, p.StreakFreezes)
+		}
+	}
+
+	// Both completions fall on July 15 in UTC but on two days in New York.
+	check("first day", complete(time.Date(2025, 7, 14, 23, 30, 0, 0, newYork), 1), 1, 1, 0)
+	check("next morning", complete(time.Date(2025, 7, 15, 8, 0, 0, 0, newYork), mission(100)), 2, 2, 0)
+	// 300 points earn a freeze; a second mission the same day is no new day.
+	check("same day", complete(time.Date(2025, 7, 15, 20, 0, 0, 0, newYork), mission(100)), 2, 2, 1)
+
+	// July 16 is missed and covered by the freeze.
+	profile := complete(time.Date(2025, 7, 17, 10, 0, 0, 0, newYork), mission(100))
+	check("after a frozen day", profile, 3, 3, 0)
+	if len(profile.Badges) != 1 || profile.Badges[0].BadgeID != "streak-3" {
+		t.Errorf("expected the streak badge, got %+v", profile.Badges)
+	}
+
+	clock.now = time.Date(2025, 7, 19, 9, 0, 0, 0, newYork)
+	profile, err = svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("could not get profile: %v", err)
+	}
+	check("after a missed day", profile, 0, 3, 0)
+	// 500 points do not earn a second freeze yet.
+	check("starting over", complete(clock.now, mission(100)), 1, 3, 0)
+	// 1200 points would earn three more freezes, but only one can be held.
+	check("capped freezes", complete(clock.now.AddDate(0, 0, 1), mission(700)), 2, 3, 1)
+
+	st, err := svc.GetStreak(ctx, 1)
+	if err != nil || st.LastActive != "2025-07-20" || st.Timezone != "America/New_York" {
+		t.Errorf("unexpected streak %+v (%v)", st, err)
+	}
+
+	// July 21 is missed: the freeze covering it is no longer held.
+	clock.now = time.Date(2025, 7, 22, 9, 0, 0, 0, newYork)
+	if st, err := svc.GetStreak(ctx, 1); err != nil || st.Current != 2 || st.Freezes != 0 {
+		t.Errorf("expected streak 2 without freezes, got %+v (%v)", st, err)
+	}
+	profile, err = svc.GetProfile(ctx, 1)
+	if err != nil {
+		t.Fatalf("could not get profile: %v", err)
+	}
+	check("with a used freeze", profile, 2, 3, 0)
+	check("frozen day kept", complete(clock.now, mission(100)), 3, 3, 0)
+}
+
+func TestSetStreakTimezone(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Streaks: store}
+	player := service.WithPrincipal(context.Background(), models.Principal{UserID: 2, Role: models.RolePlayer})
+	admin := service.WithPrincipal(context.Background(), models.Principal{UserID: 2, Role: models.RoleAdmin})
+
+	if _, err := svc.SetStreakTimezone(player, 1, "Europe/Berlin"); !errors.Is(err, service.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another user, got %v", err)
+	}
+	for _, tz := range []string{"", "Local", "Mars/Olympus_Mons"} {
+		if _, err := svc.SetStreakTimezone(admin, 1, tz); !errors.Is(err, service.ErrValidation) {
+			t.Errorf("%q: expected ErrValidation, got %v", tz, err)
+		}
+	}
+	if _, err := svc.SetStreakTimezone(admin, 999, "Europe/Berlin"); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown user, got %v", err)
+	}
+	if st, err := svc.SetStreakTimezone(admin, 1, "Europe/Berlin"); err != nil || st.Timezone != "Europe/Berlin" {
+		t.Errorf("expected the timezone to change, got %+v (%v)", st, err)
+	}
+}
+
+func TestTimezoneChangeCannotStartAnotherDay(t *testing.T) {
+	store := service.NewInMemoryStore()
+	clock := &fakeClock{now: time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC)}
+	svc := &service.MissionService{Store: store, Users: store, Streaks: store, Clock: clock}
+	ctx := service.WithPrincipal(context.Background(), models.Principal{UserID: 1, Role: models.RoleAuthor})
+
+	// At noon UTC it is July 15 at UTC-12 and already July 16 at UTC+14.
+	if _, err := svc.SetStreakTimezone(ctx, 1, "Etc/GMT+12"); err != nil {
+		t.Fatalf("could not set timezone: %v", err)
+	}
+	if _, err := svc.CompleteMission(ctx, 1, 1); err != nil {
+		t.Fatalf("could not complete mission: %v", err)
+	}
+	if _, err := svc.SetStreakTimezone(ctx, 1, "Pacific/Kiritimati"); !errors.Is(err, service.ErrValidation) {
+		t.Errorf("expected ErrValidation moving to tomorrow, got %v", err)
+	}
+	if st, err := svc.SetStreakTimezone(ctx, 1, "Europe/Berlin"); err != nil || st.Timezone != "Europe/Berlin" {
+		t.Errorf("expected a change within today, got %+v (%v)", st, err)
+	}
+
+	clock.now = clock.now.AddDate(0, 0, 1)
+	if st, err := svc.SetStreakTimezone(ctx, 1, "Pacific/Kiritimati"); err != nil || st.Timezone != "Pacific/Kiritimati" {
+		t.Errorf("expected a change the next day, got %+v (%v)", st, err)
+	}
+}
+
+func TestStreakRulesValidation(t *testing.T) {
+	rules := service.DefaultRules()
+	rules.Streaks = models.StreakRules{FreezePoints: 100}
+	var verr *service.ValidationError
+	if err := service.ValidateRules(rules); !errors.As(err, &verr) || len(verr.Fields) != 1 {
+		t.Errorf("expected max_freezes to be required, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// SubmitSolution validates the submission and puts it into the judging
+// queue. The verdict is filled in later by a WorkerPool.
+func (s *MissionService) SubmitSolution(ctx context.Context, sub models.Submission) (models.Submission, error) {
+	if !s.Judge.Supports(sub.Language) {
+		return models.Submission{}, fmt.Errorf("%w: %q", ErrUnsupportedLanguage, sub.Language)
+	}
+	if _, err := s.Users.GetUser(ctx, sub.UserID); err != nil {
+		return models.Submission{}, err
+	}
+	if _, err := s.Store.GetByID(ctx, sub.MissionID); err != nil {
+		return models.Submission{}, err
+	}
+	cases, err := s.Submissions.ListTestCases(ctx, sub.MissionID)
+	if err != nil {
+		return models.Submission{}, err
+	}
+	if len(cases) == 0 {
+		return models.Submission{}, ErrNoTestCases
+	}
+
+	sub.Status = models.SubmissionQueued
+	sub.Verdict = ""
+	sub.Detail = ""
+	return s.Submissions.AddSubmission(ctx, sub)
+}
+
+// ListTestCases returns the test cases of a mission. They reveal the
+// expected answers, so only admins and the mission's author may see them.
+func (s *MissionService) ListTestCases(ctx context.Context, missionID int) ([]models.TestCase, error) {
+	if _, err := s.editableMission(ctx, missionID); err != nil {
+		return nil, err
+	}
+	return s.Submissions.ListTestCases(ctx, missionID)
+}
+
+// ReplaceTestCases replaces the test cases of a mission. Only admins and
+// the mission's author may change them.
+func (s *MissionService) ReplaceTestCases(ctx context.Context, missionID int, cases []models.TestCase) ([]models.TestCase, error) {
+	if err := ValidateTestCases(cases); err != nil {
+		return nil, err
+	}
+	if _, err := s.editableMission(ctx, missionID); err != nil {
+		return nil, err
+	}
+	return s.Submissions.ReplaceTestCases(ctx, missionID, cases)
+}
+
+// GetSubmission returns a submission to the user who sent it or an admin.
+func (s *MissionService) GetSubmission(ctx context.Context, id int) (models.Submission, error) {
+	sub, err := s.Submissions.GetSubmission(ctx, id)
+	if err != nil {
+		return models.Submission{}, err
+	}
+	if !canManageUser(ctx, sub.UserID) {
+		return models.Submission{}, ErrForbidden
+	}
+	return sub, nil
+}
+
+// ProcessSubmission judges a claimed submission, stores the verdict and
+// then awards the mission on the first acceptance. The verdict is stored
+// first so that it is kept even when the award fails: a mission or user
+// deleted in the meantime gets no award, and other failures are logged
+// since the submission is already finished.
+func (s *MissionService) ProcessSubmission(ctx context.Context, sub models.Submission) (models.Submission, error) {
+	cases, err := s.Submissions.ListTestCases(ctx, sub.MissionID)
+	if err != nil {
+		return models.Submission{}, err
+	}
+
+	result, err := s.Judge.Judge(ctx, sub, cases)
+	if err != nil {
+		return models.Submission{}, err
+	}
+	sub.Verdict = result.Verdict
+	sub.Detail = result.Detail
+
+	if err := s.Submissions.FinishSubmission(ctx, sub); err != nil {
+		return models.Submission{}, err
+	}
+	sub.Status = models.SubmissionDone
+	if s.Logger != nil {
+		s.Logger.Info("submission judged", "submission_id", sub.ID, "mission_id", sub.MissionID, "verdict", sub.Verdict)
+	}
+	if sub.Verdict == models.VerdictAccepted {
+		s.awardSubmission(ctx, sub)
+	}
+	return sub, nil
+}
+
+// awardSubmission completes the mission of an accepted submission and
+// raises its events. Like publish it only logs failures.
+func (s *MissionService) awardSubmission(ctx context.Context, sub models.Submission) {
+	err := s.Users.AddCompletion(ctx, sub.UserID, sub.MissionID)
+	switch {
+	case err == nil:
+		s.publish(ctx, models.Event{Type: models.EventMissionCompleted, UserID: sub.UserID, MissionID: sub.MissionID})
+	case errors.Is(err, ErrAlreadyCompleted):
+	case errors.Is(err, ErrNotFound):
+		if s.Logger != nil {
+			s.Logger.Info("submission not awarded, its mission or user is gone", "submission_id", sub.ID, "mission_id", sub.MissionID)
+		}
+		return
+	default:
+		if s.Logger != nil {
+			s.Logger.Warn("could not award submission", "submission_id", sub.ID, "mission_id", sub.MissionID, "error", err)
+		}
+		return
+	}
+	s.publish(ctx, models.Event{Type: models.EventSubmissionAccepted, UserID: sub.UserID, MissionID: sub.MissionID})
+	s.recordActivity(ctx, sub.UserID)
+}
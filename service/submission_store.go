@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// ErrLeaseLost is returned when a worker reports the result of a submission
+// whose lease expired and was claimed again by another worker.
+var ErrLeaseLost = fmt.Errorf("submission lease lost: %w", ErrConflict)
+
+type SubmissionStore interface {
+	ListTestCases(ctx context.Context, missionID int) ([]models.TestCase, error)
+	ReplaceTestCases(ctx context.Context, missionID int, cases []models.TestCase) ([]models.TestCase, error)
+	AddSubmission(ctx context.Context, sub models.Submission) (models.Submission, error)
+	GetSubmission(ctx context.Context, id int) (models.Submission, error)
+	// ClaimSubmission marks the oldest queued submission (or a running one whose
+	// lease expired) as running for lease and returns it with a new
+	// LeaseToken. It returns ErrNotFound when there is nothing to do.
+	ClaimSubmission(ctx context.Context, lease time.Duration) (models.Submission, error)
+	// FinishSubmission stores the verdict of a claimed submission. It returns
+	// ErrLeaseLost unless sub.LeaseToken still holds the lease.
+	FinishSubmission(ctx context.Context, sub models.Submission) error
+	// FailSubmission records a failed judging attempt. The submission is queued
+	// again when retry is set and moved to the dead state otherwise. Like
+	// FinishSubmission it returns ErrLeaseLost for a stale sub.LeaseToken.
+	FailSubmission(ctx context.Context, sub models.Submission, reason string, retry bool) error
+}
+
+var _ SubmissionStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) ListTestCases(ctx context.Context, missionID int) ([]models.TestCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.TestCase{}, s.testCases[missionID]...), nil
+}
+
+func (s *InMemoryStore) ReplaceTestCases(ctx context.Context, missionID int, cases []models.TestCase) ([]models.TestCase, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findMission(missionID) < 0 {
+		return nil, ErrNotFound
+	}
+	if s.testCases == nil {
+		s.testCases = make(map[int][]models.TestCase)
+	}
+	stored := make([]models.TestCase, len(cases))
+	for i, tc := range cases {
+		tc.ID = s.nextTestCaseID
+		s.nextTestCaseID++
+		tc.MissionID = missionID
+		stored[i] = tc
+	}
+	s.testCases[missionID] = stored
+	return append([]models.TestCase{}, stored...), nil
+}
+
+func (s *InMemoryStore) AddSubmission(ctx context.Context, sub models.Submission) (models.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextSubmissionID++
+	sub.ID = s.nextSubmissionID
+	if sub.Status == "" {
+		sub.Status = models.SubmissionQueued
+	}
+	sub.CreatedAt = time.Now()
+	sub.UpdatedAt = sub.CreatedAt
+	s.submissions = append(s.submissions, sub)
+	return sub, nil
+}
+
+func (s *InMemoryStore) GetSubmission(ctx context.Context, id int) (models.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findSubmission(id)
+	if i < 0 {
+		return models.Submission{}, ErrNotFound
+	}
+	return s.submissions[i], nil
+}
+
+func (s *InMemoryStore) ClaimSubmission(ctx context.Context, lease time.Duration) (models.Submission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, err := randomToken()
+	if err != nil {
+		return models.Submission{}, err
+	}
+	now := time.Now()
+	for i := range s.submissions {
+		sub := &s.submissions[i]
+		if sub.Status == models.SubmissionQueued ||
+			(sub.Status == models.SubmissionRunning && sub.LockedUntil.Before(now)) {
+			sub.Status = models.SubmissionRunning
+			sub.Attempts++
+			sub.LockedUntil = now.Add(lease)
+			sub.LeaseToken = token
+			sub.UpdatedAt = now
+			return *sub, nil
+		}
+	}
+	return models.Submission{}, ErrNotFound
+}
+
+func (s *InMemoryStore) FinishSubmission(ctx context.Context, sub models.Submission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, err := s.leasedSubmission(sub)
+	if err != nil {
+		return err
+	}
+	stored.Status = models.SubmissionDone
+	stored.Verdict = sub.Verdict
+	stored.Detail = sub.Detail
+	stored.LockedUntil = time.Time{}
+	stored.LeaseToken = ""
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *InMemoryStore) FailSubmission(ctx context.Context, sub models.Submission, reason string, retry bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, err := s.leasedSubmission(sub)
+	if err != nil {
+		return err
+	}
+	stored.Status = models.SubmissionDead
+	if retry {
+		stored.Status = models.SubmissionQueued
+	}
+	stored.LastError = reason
+	stored.LockedUntil = time.Time{}
+	stored.LeaseToken = ""
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+// leasedSubmission returns the stored submission if sub's lease token still
+// holds it. Callers must hold s.mu.
+func (s *InMemoryStore) leasedSubmission(sub models.Submission) (*models.Submission, error) {
+	i := s.findSubmission(sub.ID)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	stored := &s.submissions[i]
+	if stored.Status != models.SubmissionRunning || stored.LeaseToken != sub.LeaseToken {
+		return nil, ErrLeaseLost
+	}
+	return stored, nil
+}
+
+// findSubmission returns the index of the submission in s.submissions or -1. Callers must hold s.mu.
+func (s *InMemoryStore) findSubmission(id int) int {
+	for i, sub := range s.submissions {
+		if sub.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+type UserStore interface {
+	GetUser(ctx context.Context, id int) (models.User, error)
+	AddUser(ctx context.Context, u models.User) (models.User, error)
+	AddCompletion(ctx context.Context, userID, missionID int) error
+	// ListCompletedMissions includes missions that were deleted after being
+	// completed, so the user keeps their points.
+	ListCompletedMissions(ctx context.Context, userID int) ([]models.Mission, error)
+}
+
+var _ UserStore = (*InMemoryStore)(nil)
+
+func (s *InMemoryStore) GetUser(ctx context.Context, id int) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findUser(id)
+	if i < 0 {
+		return models.User{}, ErrNotFound
+	}
+	return s.users[i], nil
+}
+
+func (s *InMemoryStore) AddUser(ctx context.Context, u models.User) (models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.users {
+		if existing.Username == u.Username {
+			return models.User{}, ErrConflict
+		}
+	}
+	u.ID = s.nextUserID
+	s.nextUserID++
+	if u.Role == "" {
+		u.Role = models.RolePlayer
+	}
+	u.CreatedAt = time.Now()
+	s.users = append(s.users, u)
+	return u, nil
+}
+
+func (s *InMemoryStore) AddCompletion(ctx context.Context, userID, missionID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addCompletion(userID, missionID)
+}
+
+func (s *InMemoryStore) addCompletion(userID, missionID int) error {
+	if s.findUser(userID) < 0 || s.findMission(missionID) < 0 {
+		return ErrNotFound
+	}
+	for _, c := range s.completions {
+		if c.UserID == userID && c.MissionID == missionID {
+			return ErrAlreadyCompleted
+		}
+	}
+	s.completions = append(s.completions, models.Completion{
+		UserID:      userID,
+		MissionID:   missionID,
+		CompletedAt: s.now(),
+	})
+	return nil
+}
+
+func (s *InMemoryStore) ListCompletedMissions(ctx context.Context, userID int) ([]models.Mission, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missions []models.Mission
+	for _, c := range s.completions {
+		if c.UserID != userID {
+			continue
+		}
+		for _, m := range s.missions {
+			if m.ID == c.MissionID {
+				missions = append(missions, m)
+				break
+			}
+		}
+	}
+	return missions, nil
+}
+
+func (s *InMemoryStore) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+	return s.Clock.Now()
+}
+
+// findUser returns the index of the user in s.users or -1. Callers must hold s.mu.
+func (s *InMemoryStore) findUser(id int) int {
+	for i, u := range s.users {
+		if u.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+const (
+	MinTitleLength       = 3
+	MaxTitleLength       = 200
+	MaxDescriptionLength = 20000
+	MinPoints            = 1
+	MaxPoints            = 10000
+	MaxTags              = 10
+	MaxTagLength         = 32
+	MaxCategoryLength    = 64
+	MaxTestCases         = 50
+	// MaxTestCaseSize limits the input and the expected output of a test case, in bytes.
+	MaxTestCaseSize = 64 << 10
+)
+
+// FieldError describes why a single field was rejected.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every invalid field of a request.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Reason
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Unwrap makes every ValidationError match ErrValidation.
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+func (e *ValidationError) add(field, reason string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Reason: reason})
+}
+
+// err returns e when it holds at least one field error and nil otherwise.
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// ValidateMission checks the user-editable fields of m.
+func ValidateMission(m models.Mission) error {
+	var v ValidationError
+
+	title := strings.TrimSpace(m.Title)
+	if n := utf8.RuneCountInString(title); n < MinTitleLength || n > MaxTitleLength {
+		v.add("title", "must be between "+strconv.Itoa(MinTitleLength)+" and "+strconv.Itoa(MaxTitleLength)+" characters")
+	}
+	if utf8.RuneCountInString(m.Description) > MaxDescriptionLength {
+		v.add("description", "must be at most "+strconv.Itoa(MaxDescriptionLength)+" characters")
+	}
+	if m.Points < MinPoints || m.Points > MaxPoints {
+		v.add("points", "must be between "+strconv.Itoa(MinPoints)+" and "+strconv.Itoa(MaxPoints))
+	}
+	switch m.Difficulty {
+	case "", models.DifficultyEasy, models.DifficultyMedium, models.DifficultyHard:
+	default:
+		v.add("difficulty", "must be one of easy, medium, hard")
+	}
+	if len(m.Tags) > MaxTags {
+		v.add("tags", "must contain at most "+strconv.Itoa(MaxTags)+" tags")
+	}
+	for i, tag := range m.Tags {
+		if n := utf8.RuneCountInString(tag); n == 0 || n > MaxTagLength || strings.TrimSpace(tag) != tag {
+			v.add("tags["+strconv.Itoa(i)+"]", "must be 1 to "+strconv.Itoa(MaxTagLength)+" characters without surrounding spaces")
+		}
+	}
+	if utf8.RuneCountInString(m.Category) > MaxCategoryLength {
+		v.add("category", "must be at most "+strconv.Itoa(MaxCategoryLength)+" characters")
+	}
+
+	return v.err()
+}
+
+// ValidateTestCases checks a replacement set of test cases. A mission needs
+// at least one case to judge submissions against.
+func ValidateTestCases(cases []models.TestCase) error {
+	var v ValidationError
+
+	if len(cases) == 0 || len(cases) > MaxTestCases {
+		v.add("tests", "must contain 1 to "+strconv.Itoa(MaxTestCases)+" test cases")
+	}
+	for i, tc := range cases {
+		field := "tests[" + strconv.Itoa(i) + "]"
+		if len(tc.Input) > MaxTestCaseSize {
+			v.add(field+".input", "must be at most "+strconv.Itoa(MaxTestCaseSize)+" bytes")
+		}
+		if tc.ExpectedOutput == "" || len(tc.ExpectedOutput) > MaxTestCaseSize {
+			v.add(field+".expected_output", "must be 1 to "+strconv.Itoa(MaxTestCaseSize)+" bytes")
+		}
+	}
+
+	return v.err()
+}
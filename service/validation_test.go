@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+func TestValidateMission(t *testing.T) {
+	valid := models.Mission{Title: "FizzBuzz", Points: 200, Difficulty: models.DifficultyMedium, Tags: []string{"loops"}}
+	if err := service.ValidateMission(valid); err != nil {
+		t.Fatalf("expected valid mission, got %v", err)
+	}
+
+	invalid := models.Mission{
+		Title:      "  ",
+		Points:     -5,
+		Difficulty: "impossible",
+		Tags:       []string{" spaced "},
+		Category:   strings.Repeat("c", service.MaxCategoryLength+1),
+	}
+	err := service.ValidateMission(invalid)
+
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, f := range validationErr.Fields {
+		got[f.Field] = true
+	}
+	for _, field := range []string{"title", "points", "difficulty", "tags[0]", "category"} {
+		if !got[field] {
+			t.Errorf("expected an error for %s, got %+v", field, validationErr.Fields)
+		}
+	}
+}
+
+func TestValidateTestCases(t *testing.T) {
+	valid := []models.TestCase{{Input: "3\n", ExpectedOutput: "9\n"}, {ExpectedOutput: "0\n"}}
+	if err := service.ValidateTestCases(valid); err != nil {
+		t.Fatalf("expected valid test cases, got %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		cases []models.TestCase
+		field string
+	}{
+		{"empty list", nil, "tests"},
+		{"too many", make([]models.TestCase, service.MaxTestCases+1), "tests"},
+		{"empty expected output", []models.TestCase{{Input: "1"}}, "tests[0].expected_output"},
+		{"oversized input", []models.TestCase{{Input: strings.Repeat("x", service.MaxTestCaseSize+1), ExpectedOutput: "x"}}, "tests[0].input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var validationErr *service.ValidationError
+			if err := service.ValidateTestCases(tt.cases); !errors.As(err, &validationErr) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			if validationErr.Fields[0].Field != tt.field {
+				t.Errorf("expected an error for %s, got %+v", tt.field, validationErr.Fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+)
+
+// WorkerPool judges queued submissions in the background.
+type WorkerPool struct {
+	Service *MissionService
+	// Workers is the number of submissions judged concurrently.
+	Workers int
+	// PollInterval is how long an idle worker waits before polling the queue again.
+	PollInterval time.Duration
+	// Lease is how long a claimed submission stays locked. A submission whose
+	// worker crashed becomes claimable again once its lease expires.
+	Lease time.Duration
+	// MaxAttempts is the number of judging attempts before a submission is moved to the dead state.
+	MaxAttempts int
+	Logger      *slog.Logger
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every worker has returned.
+func (p *WorkerPool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.Workers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			p.work(ctx, id)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func (p *WorkerPool) work(ctx context.Context, id int) {
+	for {
+		processed, err := p.processNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			p.logger().Error("submission worker failed", "worker", id, "error", err)
+		}
+		if processed {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.PollInterval):
+		}
+	}
+}
+
+// processNext claims and judges one submission. It reports whether a
+// submission was claimed.
+func (p *WorkerPool) processNext(ctx context.Context) (bool, error) {
+	sub, err := p.Service.Submissions.ClaimSubmission(ctx, p.Lease)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if sub.Attempts > p.MaxAttempts {
+		return true, p.fail(ctx, sub, "too many attempts", false)
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, p.Lease)
+	defer cancel()
+	if err := p.judge(jobCtx, sub); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			p.logger().Warn("submission lease lost", "submission_id", sub.ID, "attempt", sub.Attempts)
+			return true, nil
+		}
+		if ctx.Err() != nil {
+			// Shutting down: the lease expires and another worker picks the job up.
+			return true, nil
+		}
+		// Submissions that cannot be judged, e.g. of a mission whose test
+		// cases were removed, fail the same way on every attempt.
+		retry := sub.Attempts < p.MaxAttempts && !errors.Is(err, ErrValidation)
+		p.logger().Warn("judging attempt failed", "submission_id", sub.ID, "attempt", sub.Attempts, "retry", retry, "error", err)
+		return true, p.fail(ctx, sub, err.Error(), retry)
+	}
+	return true, nil
+}
+
+// fail records a failed attempt. Losing the lease is not an error of this
+// worker: the submission belongs to whoever claimed it since.
+func (p *WorkerPool) fail(ctx context.Context, sub models.Submission, reason string, retry bool) error {
+	err := p.Service.Submissions.FailSubmission(ctx, sub, reason, retry)
+	if errors.Is(err, ErrLeaseLost) {
+		p.logger().Warn("submission lease lost", "submission_id", sub.ID, "attempt", sub.Attempts)
+		return nil
+	}
+	return err
+}
+
+// judge runs ProcessSubmission and turns a panic into an error so that a
+// single bad job cannot take the worker down.
+func (p *WorkerPool) judge(ctx context.Context, sub models.Submission) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	_, err = p.Service.ProcessSubmission(ctx, sub)
+	return err
+}
+
+func (p *WorkerPool) logger() *slog.Logger {
+	if p.Logger != nil {
+		return p.Logger
+	}
+	return slog.Default()
+}
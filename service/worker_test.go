@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pseudoerr/mission-service/models"
+	"github.com/pseudoerr/mission-service/service"
+)
+
+type brokenJudge struct{}
+
+func (brokenJudge) Judge(ctx context.Context, sub models.Submission, cases []models.TestCase) (service.JudgeResult, error) {
+	return service.JudgeResult{}, errors.New("sandbox unavailable")
+}
+
+func (brokenJudge) Supports(language string) bool { return true }
+
+func waitForStatus(t *testing.T, store *service.InMemoryStore, id int, want models.SubmissionStatus) models.Submission {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		sub, err := store.GetSubmission(context.Background(), id)
+		if err != nil {
+			t.Fatalf("could not get submission: %v", err)
+		}
+		if sub.Status == want {
+			return sub
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("submission %d did not reach status %s", id, want)
+	return models.Submission{}
+}
+
+func runPool(t *testing.T, svc *service.MissionService) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	pool := &service.WorkerPool{
+		Service:      svc,
+		Workers:      2,
+		PollInterval: 10 * time.Millisecond,
+		Lease:        time.Minute,
+		MaxAttempts:  3,
+	}
+	go func() {
+		defer close(done)
+		pool.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestWorkerPoolJudgesQueuedSubmissions(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: newShellJudge()}
+	runPool(t, svc)
+
+	sub, err := svc.SubmitSolution(context.Background(), models.Submission{
+		UserID: 1, MissionID: 1, Language: "sh", Source: "echo nope",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := waitForStatus(t, store, sub.ID, models.SubmissionDone)
+	if done.Verdict != models.VerdictWrongAnswer {
+		t.Errorf("expected wrong_answer, got %s", done.Verdict)
+	}
+}
+
+func TestWorkerPoolDeadLettersFailingJobs(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: brokenJudge{}}
+	runPool(t, svc)
+
+	sub, err := svc.SubmitSolution(context.Background(), models.Submission{
+		UserID: 1, MissionID: 1, Language: "sh", Source: "echo hi",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dead := waitForStatus(t, store, sub.ID, models.SubmissionDead)
+	if dead.Attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", dead.Attempts)
+	}
+	if dead.LastError != "sandbox unavailable" {
+		t.Errorf("unexpected last error %q", dead.LastError)
+	}
+}
+
+func TestStaleLeaseCannotFinishSubmission(t *testing.T) {
+	store := service.NewInMemoryStore()
+	ctx := context.Background()
+	sub, err := store.AddSubmission(ctx, models.Submission{UserID: 1, MissionID: 1, Language: "sh", Source: "true"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale, err := store.ClaimSubmission(ctx, time.Millisecond)
+	if err != nil {
+		t.Fatalf("could not claim submission: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	current, err := store.ClaimSubmission(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("could not claim expired submission: %v", err)
+	}
+	if current.ID != sub.ID || current.LeaseToken == stale.LeaseToken {
+		t.Fatalf("expected a new lease on submission %d, got %+v", sub.ID, current)
+	}
+
+	stale.Verdict = models.VerdictWrongAnswer
+	if err := store.FinishSubmission(ctx, stale); !errors.Is(err, service.ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost finishing a stale lease, got %v", err)
+	}
+	if err := store.FailSubmission(ctx, stale, "timeout", true); !errors.Is(err, service.ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost failing a stale lease, got %v", err)
+	}
+
+	current.Verdict = models.VerdictAccepted
+	if err := store.FinishSubmission(ctx, current); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done, _ := store.GetSubmission(ctx, sub.ID)
+	if done.Status != models.SubmissionDone || done.Verdict != models.VerdictAccepted {
+		t.Errorf("expected the current lease's verdict, got %s/%s", done.Status, done.Verdict)
+	}
+	if err := store.FinishSubmission(ctx, current); !errors.Is(err, service.ErrLeaseLost) {
+		t.Errorf("expected ErrLeaseLost finishing twice, got %v", err)
+	}
+}
+
+func TestVerdictIsKeptWhenTheAwardFails(t *testing.T) {
+	store := service.NewInMemoryStore()
+	svc := &service.MissionService{Store: store, Users: store, Submissions: store, Judge: acceptingJudge{}}
+	ctx := context.Background()
+	// The user no longer exists, so the mission cannot be awarded.
+	if _, err := store.AddSubmission(ctx, models.Submission{UserID: 999, MissionID: 1, Language: "sh", Source: "true"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	claimed, err := store.ClaimSubmission(ctx, time.Minute)
+	if err != nil {
+		t.Fatalf("could not claim submission: %v", err)
+	}
+
+	if _, err := svc.ProcessSubmission(ctx, claimed); err != nil {
+		t.Fatalf("expected the verdict to be stored, got %v", err)
+	}
+	done, err := store.GetSubmission(ctx, claimed.ID)
+	if err != nil || done.Status != models.SubmissionDone || done.Verdict != models.VerdictAccepted {
+		t.Errorf("expected an accepted, finished submission, got %+v (%v)", done, err)
+	}
+}